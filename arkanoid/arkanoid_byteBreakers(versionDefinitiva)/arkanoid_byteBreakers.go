@@ -1,7 +1,15 @@
+//go:build !headless
+
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 	"unsafe"
 
 	"github.com/veandco/go-sdl2/sdl"
@@ -14,6 +22,38 @@ import (
 
 func main() {
 
+	frecuencia := flag.Int("tickrate", ticksPorSegundo, "ticks por segundo de la simulacion")
+	rutaNivel := flag.String("nivel", "", "archivo de un unico nivel a jugar")
+	rutaLista := flag.String("niveles", "", "archivo con la lista de niveles de la campaña (por defecto la incluida)")
+	continuar := flag.Bool("continuar", false, "empezar la campaña desde el nivel mas alto alcanzado")
+	semilla := flag.Int64("semilla", 0, "semilla del azar de la partida (0 elige una al azar)")
+	rutaGrabar := flag.String("grabar", "", "archivo donde grabar la partida para repetirla")
+	rutaReplay := flag.String("replay", "", "archivo de repeticion a reproducir (sus niveles se buscan entre los incluidos y los de -nivel o -niveles)")
+	carpetaCapturas := flag.String("screenshot", "", "carpeta donde F12 guarda la captura PNG de la pantalla (por defecto capturas en la carpeta del juego)")
+	flag.Parse()
+
+	if *semilla == 0 {
+		*semilla = time.Now().UnixNano()
+	}
+
+	// Niveles de la campaña
+	niveles, err := elegirNiveles(*rutaLista, *rutaNivel)
+	if err != nil {
+		fmt.Println("Error carga nivel:", err)
+		return
+	}
+	campania := nombreCampania(*rutaLista, *rutaNivel)
+
+	// Configuracion del jugador (lo que no se pudo leer queda con su valor por defecto)
+	conf, err := leerConfiguracion()
+	if err != nil {
+		fmt.Println("Error configuracion:", err)
+	}
+	teclas, err := teclasSDL(conf)
+	if err != nil {
+		fmt.Println("Error configuracion:", err)
+	}
+
 	// Init Texto
 	if err := ttf.Init(); err != nil {
 		fmt.Println("Error creacion texto:", err)
@@ -21,7 +61,7 @@ func main() {
 	defer ttf.Quit()
 
 	// Ventana
-	ventana, err := sdl.CreateWindow("Arkanoid ByteBreakers", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED, anchoVentana, altoVentana, sdl.WINDOW_SHOWN)
+	ventana, err := sdl.CreateWindow("Arkanoid ByteBreakers", sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED, anchoVentana*int32(conf.escala), altoVentana*int32(conf.escala), sdl.WINDOW_SHOWN)
 	if err != nil {
 		fmt.Println("Error creacion ventana:", err)
 		return
@@ -35,175 +75,637 @@ func main() {
 	}
 	defer renderizador.Destroy()
 
+	// Dibujamos siempre en el tamaño original de la ventana; con escala o en pantalla completa SDL lo agranda
+	if err := renderizador.SetLogicalSize(anchoVentana, altoVentana); err != nil {
+		fmt.Println("Error escala render:", err)
+	}
+
 	// Fuente Texto
-	font, err := ttf.OpenFont("/usr/share/fonts/truetype/dejavu/DejaVuSans-Bold.ttf", 24)
+	font, err := abrirFuente(conf.fuente, 24)
 	if err != nil {
 		fmt.Println("Error creacion texto:", err)
 	}
 	defer font.Close()
 
-	// Texturizador
-	texturizador, err := renderizador.CreateTexture(sdl.PIXELFORMAT_RGBA8888, sdl.TEXTUREACCESS_STREAMING, anchoVentana, altoVentana)
+	// Fuente chica para la tabla de puntajes
+	fontTabla, err := abrirFuente(conf.fuente, 16)
+	if err != nil {
+		fmt.Println("Error creacion texto:", err)
+	}
+	defer fontTabla.Close()
+
+	// Texturizador (con el mismo orden de bytes que la ventana donde dibujamos, ver formatoVentana)
+	texturizador, err := renderizador.CreateTexture(sdl.PIXELFORMAT_RGBA32, sdl.TEXTUREACCESS_STREAMING, anchoVentana, altoVentana)
 	if err != nil {
 		fmt.Println("Error creacion texturizador:", err)
 	}
 	defer texturizador.Destroy()
 
-	// Color Fuente
-	textColor := sdl.Color{R: 255, G: 255, B: 255, A: 255}
-
-	// Ventana donde dibujamos
-	pixelesVentana := make([]byte, anchoVentana*altoVentana*4)
+	// Ventana donde dibujamos. De la partida se redibuja y se sube a la textura solo lo que cambio
+	pixelesVentana := nuevaVentana()
+	var redibujo redibujador
 
 	// Teclado
 	teclado := sdl.GetKeyboardState()
 
-	var jugador barra
+	// Mouse y mandos (los mandos se abren con los eventos de conexion)
+	if err := sdl.InitSubSystem(sdl.INIT_GAMECONTROLLER); err != nil {
+		fmt.Println("Error inicio mandos:", err)
+	}
+	mouse := &entradaMouse{sensibilidad: &conf.sensibilidad}
+	mando := &entradaMando{mandos: make(map[sdl.JoystickID]*sdl.GameController)}
+	defer mando.cerrar()
+	mouseCapturado := false
+
+	// Partida (toda la logica del juego vive en Game, aca solo le conectamos los controles y dibujamos)
+	controles := entradaCombinada{[]InputSource{entradaTeclado{teclado, &teclas}, mouse, mando}}
+	juego := nuevoJuego(controles, niveles, *semilla)
+	juego.fijarFrecuencia(*frecuencia)
+	juego.configurarJugador(conf.velocidadBarra, conf.vidas)
+
+	// Nivel mas alto alcanzado en esta campaña (desde 1)
+	mejorNivel := leerProgreso()[campania]
+	if *continuar && mejorNivel > 1 && mejorNivel <= len(niveles) {
+		juego.empezarEn(mejorNivel - 1)
+	}
 
-	// Pelota inicial jugador
-	pelota1 := pelota{
-		pos:     pos{float32(anchoVentana) / 2, float32(altoVentana)/2 + 100},
-		radio:   5,
-		vel_x:   0,
-		vel_y:   10,
-		color:   color{255, 255, 255, 255},
-		jugador: &jugador,
+	// Repeticion: la partida se arma y se maneja con lo grabado en vez del teclado
+	var repeticion *grabacion
+	repeticionVerificada := false
+	if *rutaReplay != "" {
+		repeticion, err = leerGrabacion(*rutaReplay)
+		if err != nil {
+			fmt.Println("Error carga repeticion:", err)
+			return
+		}
+		juego, err = juegoDesdeGrabacion(repeticion, niveles)
+		if err != nil {
+			fmt.Println("Error carga repeticion:", err)
+			return
+		}
 	}
 
-	// Jugador
-	jugador = barra{
-		pos{float32(anchoVentana) / 2, float32(altoVentana) - 50},
-		100,
-		10,
-		15,
-		color{255, 255, 255, 255},
-		3,
-		0,
-		[]pelota{pelota1},
-		teclado,
+	// Grabacion: anotamos la entrada de cada tick y la guardamos al salir
+	if *rutaGrabar != "" {
+		grabando := &grabacion{
+			semilla:      juego.semilla,
+			frecuencia:   *frecuencia,
+			niveles:      huellasNiveles(juego.niveles),
+			nivelInicial: juego.nivelInicial,
+			velocidad:    int(juego.copiaJugador.vel_x),
+			vidas:        juego.copiaJugador.vida,
+		}
+		if repeticion != nil {
+			grabando.frecuencia = repeticion.frecuencia
+		}
+		juego.jugador.control = &entradaGrabada{juego.jugador.control, grabando}
+
+		defer func() {
+			grabando.cerrar(juego)
+			if err := guardarGrabacion(*rutaGrabar, grabando); err != nil {
+				fmt.Println("Error guardado repeticion:", err)
+			}
+		}()
 	}
 
-	// Copiamos los atributos del jugador y pelota inicial por si el usuario pierde para resetearlo
-	copiaJugador := jugador
-	copiaPelota1 := pelota1
+	// Tabla de mejores puntajes. Al terminar una partida que entra en la tabla pedimos el nombre
+	tablaPuntajes := leerPuntajes()
+	var nombre *entradaNombre
+	puntajeRegistrado := false
 
-	// Diagramacion mapa y resistencias todos los ladrillos
-	muro, resistenciaColor := diagramar_mapa(pos{300, 200}, 50, 20, pixelesVentana)
+	// Pantalla que se muestra. Las repeticiones, las grabaciones y -continuar van directo a la partida;
+	// si no arrancamos en el menu principal
+	pantalla := pantallaMenuPrincipal
+	if *continuar || *rutaGrabar != "" || repeticion != nil {
+		pantalla = pantallaJuego
+	}
+	// Pantalla a la que vuelven las opciones y la confirmacion de salida
+	volverA := pantalla
+	salir := false
+	pantallaCompleta := false
+
+	// Mientras se graba o se repite una partida no se puede empezar otra desde el menu
+	// (la grabacion quedaria con dos partidas mezcladas)
+	puedeReiniciar := *rutaGrabar == "" && repeticion == nil
+
+	// Se arma mas abajo porque sus opciones tambien abren otras pantallas
+	var armarPrincipal func()
+
+	abrir := func(destino tipoPantalla, menu *menu) {
+		if destino == pantallaOpciones || destino == pantallaSalir {
+			volverA = pantalla
+		}
+		if destino == pantallaMenuPrincipal {
+			armarPrincipal()
+		}
+		pantalla = destino
+		menu.seleccion = 0
+	}
+	reanudar := func() {
+		juego.reanudar()
+		pantalla = pantallaJuego
+	}
+	// Al empezar una partida desde el menu toma la velocidad y las vidas de la configuracion actual
+	empezar := func(indice int) {
+		juego.configurarJugador(conf.velocidadBarra, conf.vidas)
+		juego.empezarEn(indice)
+		juego.reiniciar()
+		pantalla = pantallaJuego
+	}
 
-	// Copia mapa para restaurarlo cuando el usuario pierde 3 vidas
-	copiaMuro := replicaMuro(muro)
+	menuOpciones := &menu{titulo: "OPCIONES"}
+	menuSalir := &menu{titulo: "¿SALIR DEL JUEGO?"}
+	menuPrincipal := &menu{titulo: "ARKANOID BYTEBREAKERS"}
+	menuPausa := &menu{titulo: "PAUSA"}
+
+	// Cada cambio en las opciones se guarda en el archivo de configuracion
+	guardar := func() {
+		if err := guardarConfiguracion(conf); err != nil {
+			fmt.Println("Error guardado configuracion:", err)
+		}
+	}
+	// Accion que espera la proxima tecla para asignarsela (cantidadAcciones si no espera ninguna)
+	esperandoTecla := cantidadAcciones
+
+	// Opcion numerica de la configuracion que las flechas laterales mueven de a 'paso' entre sus limites
+	opcionNumero := func(texto string, valor *int, minimo, maximo, paso int, aplicar func()) opcionMenu {
+		return opcionMenu{
+			texto: texto,
+			valor: func() string { return strconv.Itoa(*valor) },
+			cambiar: func(delta int) {
+				nuevo := limitar(*valor+delta*paso, minimo, maximo)
+				if nuevo == *valor {
+					return
+				}
+				*valor = nuevo
+				if aplicar != nil {
+					aplicar()
+				}
+				guardar()
+			},
+		}
+	}
+
+	menuOpciones.opciones = []opcionMenu{
+		// La velocidad y las vidas se aplican al empezar la proxima partida
+		opcionNumero("VELOCIDAD BARRA", &conf.velocidadBarra, minVelocidadBarra, maxVelocidadBarra, 1, nil),
+		opcionNumero("VIDAS", &conf.vidas, 1, maxVidas, 1, nil),
+		opcionNumero("SENSIBILIDAD MOUSE", &conf.sensibilidad, minSensibilidad, maxSensibilidad, 10, nil),
+		opcionNumero("ESCALA VENTANA", &conf.escala, 1, maxEscala, 1, func() {
+			ventana.SetSize(anchoVentana*int32(conf.escala), altoVentana*int32(conf.escala))
+		}),
+	}
+	for i := accion(0); i < cantidadAcciones; i++ {
+		accionTecla := i
+		menuOpciones.opciones = append(menuOpciones.opciones, opcionMenu{
+			texto: "TECLA " + strings.ToUpper(nombresAcciones[accionTecla]),
+			valor: func() string {
+				if esperandoTecla == accionTecla {
+					return "..."
+				}
+				return conf.teclas[accionTecla]
+			},
+			accion: func() { esperandoTecla = accionTecla },
+		})
+	}
+	menuOpciones.opciones = append(menuOpciones.opciones, []opcionMenu{
+		{
+			texto: "PANTALLA COMPLETA",
+			valor: func() string {
+				if pantallaCompleta {
+					return "SI"
+				}
+				return "NO"
+			},
+			cambiar: func(delta int) {
+				var modo uint32
+				if !pantallaCompleta {
+					modo = sdl.WINDOW_FULLSCREEN_DESKTOP
+				}
+				if err := ventana.SetFullscreen(modo); err != nil {
+					fmt.Println("Error pantalla completa:", err)
+					return
+				}
+				pantallaCompleta = !pantallaCompleta
+			},
+		},
+		{texto: "VOLVER", accion: func() { pantalla = volverA }},
+	}...)
+	// ENTER sobre la pantalla completa tambien la cambia
+	menuOpciones.opciones[len(menuOpciones.opciones)-2].accion = func() { menuOpciones.cambiar(1) }
+
+	menuSalir.opciones = []opcionMenu{
+		{texto: "NO", accion: func() { pantalla = volverA }},
+		{texto: "SI", accion: func() { salir = true }},
+	}
+
+	// El menu principal se arma cada vez que se abre: CONTINUAR aparece con el nivel mas alto alcanzado hasta ahora
+	armarPrincipal = func() {
+		menuPrincipal.opciones = []opcionMenu{
+			{texto: "JUGAR", accion: func() { empezar(0) }},
+		}
+		if mejorNivel > 1 && mejorNivel <= len(niveles) {
+			nivelGuardado := mejorNivel
+			menuPrincipal.opciones = append(menuPrincipal.opciones, opcionMenu{
+				texto:  fmt.Sprintf("CONTINUAR (NIVEL %d)", nivelGuardado),
+				accion: func() { empezar(nivelGuardado - 1) },
+			})
+		}
+		menuPrincipal.opciones = append(menuPrincipal.opciones,
+			opcionMenu{texto: "OPCIONES", accion: func() { abrir(pantallaOpciones, menuOpciones) }},
+			opcionMenu{texto: "SALIR", accion: func() { abrir(pantallaSalir, menuSalir) }},
+		)
+	}
+	armarPrincipal()
+
+	menuPausa.opciones = []opcionMenu{
+		{texto: "CONTINUAR", accion: reanudar},
+		{texto: "OPCIONES", accion: func() { abrir(pantallaOpciones, menuOpciones) }},
+	}
+	if puedeReiniciar {
+		menuPausa.opciones = append(menuPausa.opciones, opcionMenu{
+			texto:  "MENU PRINCIPAL",
+			accion: func() { abrir(pantallaMenuPrincipal, menuPrincipal) },
+		})
+	}
+	menuPausa.opciones = append(menuPausa.opciones,
+		opcionMenu{texto: "SALIR", accion: func() { abrir(pantallaSalir, menuSalir) }},
+	)
+
+	menus := map[tipoPantalla]*menu{
+		pantallaMenuPrincipal: menuPrincipal,
+		pantallaPausa:         menuPausa,
+		pantallaOpciones:      menuOpciones,
+		pantallaSalir:         menuSalir,
+	}
+
+	// Reloj del bucle de paso fijo: acumulamos el tiempo real y lo consumimos en ticks de juego.dt
+	frecuenciaReloj := float64(sdl.GetPerformanceFrequency())
+	ultimoReloj := sdl.GetPerformanceCounter()
+	var acumulado float64
+
+	// Teclas de la interfaz (no de la partida): mueven los menus, pausan y reanudan. Los botones del mando
+	// llegan aca traducidos a la tecla equivalente
+	navegar := func(codigo sdl.Scancode) {
+		// Navegacion de los menus
+		if menuActual := menus[pantalla]; menuActual != nil {
+			switch codigo {
+			case sdl.SCANCODE_UP:
+				menuActual.mover(-1)
+			case sdl.SCANCODE_DOWN:
+				menuActual.mover(1)
+			case sdl.SCANCODE_LEFT:
+				menuActual.cambiar(-1)
+			case sdl.SCANCODE_RIGHT:
+				menuActual.cambiar(1)
+			case sdl.SCANCODE_RETURN:
+				menuActual.elegir()
+			case sdl.SCANCODE_ESCAPE:
+				switch pantalla {
+				case pantallaPausa:
+					reanudar()
+				case pantallaMenuPrincipal:
+					abrir(pantallaSalir, menuSalir)
+				default:
+					pantalla = volverA
+				}
+			}
+			return
+		}
+
+		// En la partida ESC o la tecla de pausa la pausan; al terminarla ESC vuelve al menu principal
+		if codigo == sdl.SCANCODE_ESCAPE || codigo == teclas[accionPausa] {
+			if juego.pausar() {
+				abrir(pantallaPausa, menuPausa)
+			} else if codigo == sdl.SCANCODE_ESCAPE {
+				if puedeReiniciar {
+					abrir(pantallaMenuPrincipal, menuPrincipal)
+				} else {
+					abrir(pantallaSalir, menuSalir)
+				}
+			}
+		}
+	}
+
+	// Tecla equivalente a cada boton del mando en la interfaz (B vuelve atras y START pausa y reanuda, los dos como ESC)
+	botonesMando := map[uint8]sdl.Scancode{
+		sdl.CONTROLLER_BUTTON_DPAD_UP:    sdl.SCANCODE_UP,
+		sdl.CONTROLLER_BUTTON_DPAD_DOWN:  sdl.SCANCODE_DOWN,
+		sdl.CONTROLLER_BUTTON_DPAD_LEFT:  sdl.SCANCODE_LEFT,
+		sdl.CONTROLLER_BUTTON_DPAD_RIGHT: sdl.SCANCODE_RIGHT,
+		sdl.CONTROLLER_BUTTON_A:          sdl.SCANCODE_RETURN,
+		sdl.CONTROLLER_BUTTON_B:          sdl.SCANCODE_ESCAPE,
+		sdl.CONTROLLER_BUTTON_START:      sdl.SCANCODE_ESCAPE,
+	}
 
 	// -----------------------FOTOGRAMAS-------------------------------
-	for {
+	for !salir {
 
 		for evento := sdl.PollEvent(); evento != nil; evento = sdl.PollEvent() {
-			switch evento.(type) {
+			switch e := evento.(type) {
 			case *sdl.QuitEvent:
 				return
+
+			// Escritura del nombre para la tabla de puntajes
+			case *sdl.TextInputEvent:
+				if nombre != nil {
+					nombre.escribir(e.GetText())
+				}
+			case *sdl.KeyboardEvent:
+				if e.Type != sdl.KEYDOWN {
+					break
+				}
+				// F12 guarda el ultimo fotograma dibujado en cualquier pantalla
+				if e.Keysym.Scancode == sdl.SCANCODE_F12 {
+					if ruta, err := guardarCaptura(*carpetaCapturas, pixelesVentana); err != nil {
+						fmt.Println("Error captura:", err)
+					} else {
+						fmt.Println("Captura guardada en", ruta)
+					}
+					break
+				}
+				if nombre != nil {
+					switch e.Keysym.Scancode {
+					case sdl.SCANCODE_BACKSPACE:
+						nombre.borrar()
+					case sdl.SCANCODE_RETURN:
+						tablaPuntajes = tablaPuntajes.agregar(juego.puntajeFinal(nombre.nombre()))
+						if err := guardarPuntajes(tablaPuntajes); err != nil {
+							fmt.Println("Error guardado puntajes:", err)
+						}
+						nombre = nil
+						sdl.StopTextInput()
+					}
+					break
+				}
+
+				// Asignacion de una tecla desde las opciones (ESC cancela). Si la tecla ya era de otra accion
+				// intercambiamos las dos, asi nunca quedan dos acciones con la misma tecla
+				if esperandoTecla < cantidadAcciones {
+					if e.Keysym.Scancode != sdl.SCANCODE_ESCAPE {
+						for otra := range teclas {
+							if teclas[otra] == e.Keysym.Scancode {
+								teclas[otra], conf.teclas[otra] = teclas[esperandoTecla], conf.teclas[esperandoTecla]
+							}
+						}
+						teclas[esperandoTecla] = e.Keysym.Scancode
+						conf.teclas[esperandoTecla] = sdl.GetScancodeName(e.Keysym.Scancode)
+						guardar()
+					}
+					esperandoTecla = cantidadAcciones
+					break
+				}
+
+				navegar(e.Keysym.Scancode)
+
+			// Mouse: el movimiento solo cuenta durante la partida (en los menus el cursor queda libre)
+			case *sdl.MouseMotionEvent:
+				if pantalla == pantallaJuego && nombre == nil {
+					mouse.evento(e)
+				}
+			case *sdl.MouseButtonEvent:
+				mouse.evento(e)
+
+			// Mandos: conexion en caliente y botones para los menus
+			case *sdl.ControllerDeviceEvent:
+				mando.evento(e)
+			case *sdl.ControllerButtonEvent:
+				if e.Type != sdl.CONTROLLERBUTTONDOWN || nombre != nil || esperandoTecla < cantidadAcciones {
+					break
+				}
+				if codigo, ok := botonesMando[e.Button]; ok {
+					navegar(codigo)
+				}
+
+			// Si el renderizador perdio las texturas hay que volver a subir la ventana entera
+			case *sdl.RenderEvent:
+				redibujo.invalidar()
 			}
 		}
 
-		limpieza(pixelesVentana)
+		// El mouse se captura en modo relativo solo mientras se juega
+		if capturar := pantalla == pantallaJuego && nombre == nil; capturar != mouseCapturado {
+			sdl.SetRelativeMouseMode(capturar)
+			mouseCapturado = capturar
+		}
 
-		// Movimiento jugador
-		llamarMovimiento(&jugador)
+		// Cuando termina la partida vemos si el puntaje entra en la tabla (una sola vez por partida)
+		partidaTerminada := juego.estado == win || juego.estado == loose
+		if partidaTerminada && !puntajeRegistrado && repeticion == nil {
+			puntajeRegistrado = true
+			if tablaPuntajes.entra(juego.jugador.score) {
+				nombre = &entradaNombre{}
+				sdl.StartTextInput()
+			}
+		}
+		if !partidaTerminada {
+			puntajeRegistrado = false
+		}
 
-		// Grafica ladrillos (Y verificamos si el usuario gano)
-		graficarLadrillos(muro, pixelesVentana)
+		// Si el usuario gano, la tecla de lanzar cierra el juego
+		if pantalla == pantallaJuego && juego.estado == win && nombre == nil && teclado[teclas[accionLanzar]] != 0 {
+			return
+		}
 
-		//Graficar pelotas
-		graficarPelotas(jugador, pixelesVentana)
+		// En los menus la partida queda congelada: no sumamos el tiempo (asi el dibujo no salta al volver)
+		ahora := sdl.GetPerformanceCounter()
+		if pantalla == pantallaJuego {
+			acumulado += float64(ahora-ultimoReloj) / frecuenciaReloj
+		}
+		ultimoReloj = ahora
 
-		// Dibujar jugador
-		llamarDibujar(&jugador, pixelesVentana)
+		// Si la ventana se trabo no intentamos recuperar mas de un cuarto de segundo de simulacion
+		if acumulado > 0.25 {
+			acumulado = 0.25
+		}
 
-		renderizador.Copy(texturizador, nil, nil)
-		if err != nil {
-			fmt.Println("Error copia textura en renderizador:", err)
+		// Mientras escribe el nombre la partida no avanza (SPACE es parte del nombre, no reinicia)
+		if nombre != nil {
+			acumulado = 0
 		}
 
-		// Switch STATE juego
-		switch state {
-		// Juego en pausa
-		case start:
-			if teclado[sdl.SCANCODE_SPACE] != 0 {
-				state = play
+		for acumulado >= float64(juego.dt) {
+			// Al terminar la repeticion la partida queda quieta
+			if repeticion != nil && juego.tick >= len(repeticion.entradas) {
+				acumulado = 0
+				break
 			}
 
-		// Si el usuario esta jugando
-		case play:
-			estadoLadrillos(jugador, muro, pixelesVentana, resistenciaColor)
+			juego.Step()
+			acumulado -= float64(juego.dt)
+		}
 
-			movimientoPelotas(jugador)
+		// Cuando se consumio toda la repeticion verificamos una vez que haya terminado igual que la grabacion
+		if repeticion != nil && !repeticionVerificada && juego.tick >= len(repeticion.entradas) {
+			repeticionVerificada = true
+			if err := repeticion.coincide(juego); err != nil {
+				fmt.Println("Error repeticion:", err)
+			} else {
+				fmt.Println("Repeticion terminada: coincide con la grabacion")
+			}
+		}
 
-		// Si el usuario gano
-		case win:
-			limpieza(pixelesVentana)
-			textoVictoria := fmt.Sprintf("YOU WIN! SCORE: %d", jugador.score)
-			surface, err := font.RenderUTF8Solid(textoVictoria, textColor)
-			if err != nil {
-				fmt.Println("Error creacion texto:", err)
+		// Guardamos el progreso cada vez que se supera un nivel (queda habilitado el siguiente)
+		for _, evento := range juego.tomarEventos() {
+			siguiente := evento.nivel + 2
+			if evento.tipo == eventoNivelSuperado && siguiente <= len(juego.niveles) && siguiente > mejorNivel {
+				mejorNivel = siguiente
+				if err := guardarProgreso(campania, mejorNivel); err != nil {
+					fmt.Println("Error guardado progreso:", err)
+				}
 			}
-			defer surface.Free()
+		}
 
-			texturaTexto, err := renderizador.CreateTextureFromSurface(surface)
+		// Los menus que se abren desde la partida y los textos del final se muestran sobre el nivel congelado, oscurecido
+		// por el velo de dibujarPartida; el principal sobre la ventana en negro.
+		// El jugador y las pelotas se dibujan entre el tick anterior y el actual segun el tiempo sobrante
+		mostrarPartida := pantalla == pantallaJuego || pantalla == pantallaPausa ||
+			(pantalla == pantallaOpciones || pantalla == pantallaSalir) && volverA != pantallaMenuPrincipal
+		sucios := []rectangulo{ventanaEntera}
+		if mostrarPartida {
+			sucios = redibujo.dibujar(juego, float32(acumulado/float64(juego.dt)), pixelesVentana)
+		} else {
+			pintarRect(ventanaEntera, color{0, 0, 0, 255}, pixelesVentana)
+			redibujo.invalidar()
+		}
+
+		for _, rect := range sucios {
+			pixelsPointer := unsafe.Pointer(&pixelesVentana[(rect.y0*anchoVentana+rect.x0)*4])
+			rectTextura := sdl.Rect{X: int32(rect.x0), Y: int32(rect.y0), W: int32(rect.x1 - rect.x0), H: int32(rect.y1 - rect.y0)}
+
+			err = texturizador.Update(&rectTextura, pixelsPointer, int(anchoVentana)*4)
 			if err != nil {
-				fmt.Println("Error textura texto:", err)
+				fmt.Println("Error actualizacion texturizador:", err)
 			}
-			defer texturaTexto.Destroy()
+		}
 
-			renderizador.Copy(texturaTexto, nil, &sdl.Rect{X: (anchoVentana / 2) - 150, Y: altoVentana / 2, W: surface.W, H: surface.H})
+		err = renderizador.Copy(texturizador, nil, nil)
+		if err != nil {
+			fmt.Println("Error copia textura en renderizador:", err)
+		}
 
-			if teclado[sdl.SCANCODE_SPACE] != 0 {
-				return
-			}
+		// Menu de la pantalla actual (la partida no muestra los textos de su estado por debajo)
+		if menuActual := menus[pantalla]; menuActual != nil {
+			dibujarMenu(renderizador, font, menuActual)
+			renderizador.Present()
+			sdl.Delay(1)
+			continue
+		}
+
+		switch juego.estado {
+		// Si el usuario supero un nivel
+		case cleared:
+			textoNivel := fmt.Sprintf("LEVEL %d CLEARED! SCORE: %d", juego.nivelActual+1, juego.jugador.score)
+			dibujarTexto(renderizador, font, textoNivel, (anchoVentana/2)-190, altoVentana/2)
+
+		// Si el usuario gano la campaña
+		case win:
+			textoVictoria := fmt.Sprintf("YOU WIN! SCORE: %d", juego.jugador.score)
+			dibujarTexto(renderizador, font, textoVictoria, (anchoVentana/2)-150, 120)
+			dibujarPuntajes(renderizador, font, fontTabla, tablaPuntajes, nombre)
 
 		// Si el usuario perdio
 		case loose:
-			limpieza(pixelesVentana)
-			for index, value := range copiaMuro {
-				muro[index] = value
-			}
-
-			textoDerrota := fmt.Sprintf("SCORE: %d", jugador.score)
+			textoDerrota := fmt.Sprintf("SCORE: %d", juego.jugador.score)
+			dibujarTexto(renderizador, font, textoDerrota, (anchoVentana/2)-70, 120)
+			dibujarPuntajes(renderizador, font, fontTabla, tablaPuntajes, nombre)
+		}
 
-			surface, err := font.RenderUTF8Solid(textoDerrota, textColor)
-			if err != nil {
-				fmt.Println("Error creacion texto:", err)
-			}
-			defer surface.Free()
+		renderizador.Present()
 
-			texturaTexto, err := renderizador.CreateTextureFromSurface(surface)
-			if err != nil {
-				fmt.Println("Error textura texto:", err)
-			}
-			defer texturaTexto.Destroy()
+		sdl.Delay(1)
+	}
 
-			renderizador.Copy(texturaTexto, nil, &sdl.Rect{X: (anchoVentana / 2) - 70, Y: altoVentana / 2, W: surface.W, H: surface.H})
+}
 
-			if teclado[sdl.SCANCODE_SPACE] != 0 {
-				jugador = copiaJugador
-				jugador.pelotas = []pelota{copiaPelota1}
+// Dibuja la tabla de mejores puntajes con el detalle por nivel, o el nombre que se esta escribiendo si entro en la tabla
+func dibujarPuntajes(renderizador *sdl.Renderer, font, fontTabla *ttf.Font, tabla tablaPuntajes, nombre *entradaNombre) {
+	if nombre != nil {
+		dibujarTexto(renderizador, font, "NEW HIGH SCORE!", (anchoVentana/2)-110, altoVentana/2-40)
+		dibujarTexto(renderizador, font, "NAME: "+nombre.texto+"_", (anchoVentana/2)-150, altoVentana/2)
+		return
+	}
 
-				for index, value := range copiaMuro {
-					muro[index] = value
-				}
+	dibujarTexto(renderizador, font, "HIGH SCORES", (anchoVentana/2)-85, 200)
+	for i, p := range tabla {
+		y := int32(250 + i*50)
+		dibujarTexto(renderizador, fontTabla, fmt.Sprintf("%2d. %-12s %6d", i+1, p.nombre, p.score), 80, y)
+		if detalle := p.detalle(); detalle != "" {
+			dibujarTexto(renderizador, fontTabla, detalle, 120, y+20)
+		}
+	}
+}
 
-				state = start
-			}
+// Dibuja el titulo y las opciones del menu centrados en la ventana, con la seleccionada marcada
+func dibujarMenu(renderizador *sdl.Renderer, font *ttf.Font, menu *menu) {
+	separacion := int32(50)
+	y := altoVentana/2 - separacion*int32(len(menu.opciones)+2)/2
 
+	dibujarTextoCentrado(renderizador, font, menu.titulo, y)
+	for i, opcion := range menu.opciones {
+		texto := opcion.etiqueta()
+		if i == menu.seleccion {
+			texto = "> " + texto + " <"
 		}
+		dibujarTextoCentrado(renderizador, font, texto, y+separacion*int32(i+2))
+	}
+}
 
-		pixelsPointer := unsafe.Pointer(&pixelesVentana[0])
+// Dibuja un texto centrado horizontalmente en la altura 'y'
+func dibujarTextoCentrado(renderizador *sdl.Renderer, font *ttf.Font, texto string, y int32) {
+	ancho, _, err := font.SizeUTF8(texto)
+	if err != nil {
+		fmt.Println("Error medida texto:", err)
+		return
+	}
+	dibujarTexto(renderizador, font, texto, (anchoVentana-int32(ancho))/2, y)
+}
 
-		texturizador.Update(nil, pixelsPointer, int(anchoVentana)*4)
+// Guardamos la ventana como PNG con la fecha y hora en el nombre. Sin carpeta van a capturas en la carpeta del juego.
+// Los textos los dibuja SDL aparte, asi que la captura tiene solo lo que se dibuja en la ventana
+func guardarCaptura(carpeta string, ventana []byte) (string, error) {
+	if carpeta == "" {
+		directorio, err := directorioJuego()
 		if err != nil {
-			fmt.Println("Error actualizacion texturizador:", err)
+			return "", err
 		}
+		carpeta = filepath.Join(directorio, "capturas")
+	}
+	if err := os.MkdirAll(carpeta, 0o755); err != nil {
+		return "", err
+	}
 
-		renderizador.Present()
+	ruta := filepath.Join(carpeta, "captura-"+time.Now().Format("20060102-150405.000")+".png")
+	return ruta, guardarPNG(ruta, ventana)
+}
+
+// Abrimos la fuente de la configuracion; si no se puede probamos con la fuente por defecto
+func abrirFuente(ruta string, tamanio int) (*ttf.Font, error) {
+	font, err := ttf.OpenFont(ruta, tamanio)
+	if err == nil || ruta == configuracionPorDefecto().fuente {
+		return font, err
+	}
+	fmt.Println("Error fuente de la configuracion:", err)
+	return ttf.OpenFont(configuracionPorDefecto().fuente, tamanio)
+}
 
-		sdl.Delay(16)
+// Dibuja un texto con la fuente en la posicion (x, y) del renderizador
+func dibujarTexto(renderizador *sdl.Renderer, font *ttf.Font, texto string, x, y int32) {
+	textColor := sdl.Color{R: 255, G: 255, B: 255, A: 255}
+
+	surface, err := font.RenderUTF8Solid(texto, textColor)
+	if err != nil {
+		fmt.Println("Error creacion texto:", err)
+		return
+	}
+	defer surface.Free()
+
+	texturaTexto, err := renderizador.CreateTextureFromSurface(surface)
+	if err != nil {
+		fmt.Println("Error textura texto:", err)
+		return
 	}
+	defer texturaTexto.Destroy()
 
+	renderizador.Copy(texturaTexto, nil, &sdl.Rect{X: x, Y: y, W: surface.W, H: surface.H})
 }
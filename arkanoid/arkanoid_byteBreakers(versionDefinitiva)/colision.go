@@ -0,0 +1,369 @@
+package main
+
+import "math"
+
+// ------------------------------------------------------------------------------------
+// ----------------------------------COLISIONES-----------------------------------------
+// ------------------------------------------------------------------------------------
+
+// Cantidad maxima de contactos que resolvemos para una pelota en un mismo tick
+const maxContactos = 8
+
+// Velocidad vertical minima (pixeles por fotograma) para que los rebotes en esquinas no dejen la pelota casi horizontal
+const velocidadVerticalMinima = 4
+
+// Velocidades horizontales que toma la pelota segun el segmento de la barra donde rebota
+var velocidades_x = []int{-11, -9, -7, -5, -3, -1, 1, 3, 5, 7, 9, 11}
+
+// Que fue lo que toco la pelota
+type tipoContacto int
+
+const (
+	sinContacto tipoContacto = iota
+	contactoPared
+	contactoBarra
+	contactoLadrillo
+	contactoEnemigo
+	contactoJefe
+)
+
+// Primer contacto de la pelota a lo largo de su desplazamiento
+type contacto struct {
+	tipo   tipoContacto
+	t      float32 // Fraccion del desplazamiento (0 a 1) en la que toca
+	normal pos     // Normal de la cara (o esquina) tocada, apunta hacia afuera
+	indice int     // Indice en el muro (contactoLadrillo) o en los enemigos (contactoEnemigo)
+}
+
+// Lo que puede tocar la pelota ademas de las paredes y la barra
+type obstaculos struct {
+	muro     []ladrillo
+	grilla   *grilla // Broadphase del muro (nil para probar todos los ladrillos)
+	enemigos []enemigo
+	jefe     *jefe // nil si el nivel no tiene jefe
+}
+
+// Metodo que mueve la pelota dt segundos resolviendo en orden cada contacto con las paredes, la barra, los
+// ladrillos del muro que tengan resistencia, los enemigos y el jefe. Si hay grilla solo se prueban los ladrillos
+// de las celdas que toca el recorrido, si no todos. Por cada ladrillo, enemigo o jefe tocado llama a golpe
+func (bola *pelota) barrido(dt float32, obstaculos obstaculos, golpe func(contacto)) {
+	muro, grilla := obstaculos.muro, obstaculos.grilla
+	// La pelota pegada no se mueve por su cuenta, acompaña a la barra
+	if bola.pegada {
+		bola.seguirBarra()
+		return
+	}
+
+	fraccion := float32(1)
+
+	for contactos := 0; contactos < maxContactos && fraccion > 0; contactos++ {
+		desplazamiento := pos{bola.vel_x * dt * frecuenciaBase * fraccion, bola.vel_y * dt * frecuenciaBase * fraccion}
+
+		primero := bola.contactoParedes(desplazamiento)
+
+		if bola.jugador != nil {
+			jugador := bola.jugador
+			c, ok := barridoCirculoRect(bola.pos, desplazamiento, bola.radio, jugador.pos, float32(jugador.ancho)/2, float32(jugador.alto)/2)
+			if ok && c.t < primero.t {
+				primero = c
+				primero.tipo = contactoBarra
+			}
+		}
+
+		probarLadrillo := func(i int) {
+			if muro[i].resist == 0 {
+				return
+			}
+			c, ok := barridoCirculoRect(bola.pos, desplazamiento, bola.radio, muro[i].pos, float32(muro[i].ancho)/2, float32(muro[i].alto)/2)
+			if ok && c.t < primero.t {
+				primero = c
+				primero.tipo = contactoLadrillo
+				primero.indice = i
+			}
+		}
+
+		if grilla != nil {
+			// Rectangulo que cubre todo el recorrido de la pelota en este tramo
+			destino := pos{bola.pos.x + desplazamiento.x, bola.pos.y + desplazamiento.y}
+			minimo := pos{min32(bola.pos.x, destino.x) - bola.radio, min32(bola.pos.y, destino.y) - bola.radio}
+			maximo := pos{max32(bola.pos.x, destino.x) + bola.radio, max32(bola.pos.y, destino.y) + bola.radio}
+			for _, i := range grilla.consultar(minimo, maximo) {
+				probarLadrillo(i)
+			}
+		} else {
+			for i := range muro {
+				probarLadrillo(i)
+			}
+		}
+
+		for i := range obstaculos.enemigos {
+			enemigo := &obstaculos.enemigos[i]
+			if enemigo.destruido {
+				continue
+			}
+			c, ok := barridoCirculoCirculo(bola.pos, desplazamiento, bola.radio, enemigo.pos, enemigo.radio)
+			if ok && c.t < primero.t {
+				primero = c
+				primero.tipo = contactoEnemigo
+				primero.indice = i
+			}
+		}
+
+		if jefe := obstaculos.jefe; jefe != nil && jefe.vida > 0 {
+			c, ok := barridoCirculoRect(bola.pos, desplazamiento, bola.radio, jefe.pos, float32(jefe.ancho)/2, float32(jefe.alto)/2)
+			if ok && c.t < primero.t {
+				primero = c
+				primero.tipo = contactoJefe
+			}
+		}
+
+		if primero.tipo == sinContacto {
+			bola.pos.x += desplazamiento.x
+			bola.pos.y += desplazamiento.y
+			return
+		}
+
+		// Avanzamos hasta el punto de contacto y rebotamos
+		bola.pos.x += desplazamiento.x * primero.t
+		bola.pos.y += desplazamiento.y * primero.t
+		fraccion *= 1 - primero.t
+
+		switch primero.tipo {
+		case contactoBarra:
+			// Desde arriba la barra decide el angulo segun el segmento, de costado rebota como una pared
+			// Con el poder atrapar se queda pegada hasta que el jugador la lance
+			if primero.normal.y < 0 && bola.jugador.atrapa {
+				bola.pegar()
+				return
+			} else if primero.normal.y < 0 {
+				configuracion_velocidad(bola, bola.jugador, velocidades_x)
+			} else {
+				bola.reflejar(primero.normal)
+			}
+		case contactoLadrillo, contactoEnemigo, contactoJefe:
+			bola.reflejar(primero.normal)
+			bola.limitarAngulo()
+			if golpe != nil {
+				golpe(primero)
+			}
+		default:
+			bola.reflejar(primero.normal)
+			bola.limitarAngulo()
+		}
+	}
+}
+
+// Primer contacto de la pelota con las paredes izquierda, derecha y superior de la ventana
+func (bola *pelota) contactoParedes(desplazamiento pos) contacto {
+	primero := contacto{tipo: sinContacto, t: 2}
+
+	paredes := []struct {
+		distancia float32 // Distancia que le falta a la pelota para tocar la pared (negativa si ya la paso)
+		avance    float32 // Cuanto se acerca a la pared en este desplazamiento
+		normal    pos
+	}{
+		{bola.pos.x - bola.radio, -desplazamiento.x, pos{1, 0}},
+		{float32(anchoVentana) - bola.pos.x - bola.radio, desplazamiento.x, pos{-1, 0}},
+		{bola.pos.y - bola.radio, -desplazamiento.y, pos{0, 1}},
+	}
+
+	for _, pared := range paredes {
+		if pared.avance <= 0 {
+			continue
+		}
+		t := pared.distancia / pared.avance
+		if t < 0 {
+			t = 0
+		}
+		if t <= 1 && t < primero.t {
+			primero = contacto{tipo: contactoPared, t: t, normal: pared.normal}
+		}
+	}
+
+	return primero
+}
+
+// Metodo que refleja la velocidad de la pelota respecto de la normal (solo si se esta acercando)
+func (bola *pelota) reflejar(normal pos) {
+	producto := bola.vel_x*normal.x + bola.vel_y*normal.y
+	if producto >= 0 {
+		return
+	}
+	bola.vel_x -= 2 * producto * normal.x
+	bola.vel_y -= 2 * producto * normal.y
+}
+
+// Metodo que asegura la velocidad vertical minima manteniendo la rapidez total de la pelota
+func (bola *pelota) limitarAngulo() {
+	if abs32(bola.vel_y) >= velocidadVerticalMinima {
+		return
+	}
+
+	rapidez := math.Sqrt(float64(bola.vel_x*bola.vel_x + bola.vel_y*bola.vel_y))
+	if rapidez <= velocidadVerticalMinima {
+		return
+	}
+
+	bola.vel_y = signo32(bola.vel_y) * velocidadVerticalMinima
+	bola.vel_x = signo32(bola.vel_x) * float32(math.Sqrt(rapidez*rapidez-velocidadVerticalMinima*velocidadVerticalMinima))
+}
+
+// Barrido de un circulo de radio 'radio' que parte de 'origen' y se desplaza 'desplazamiento' contra el rectangulo
+// con centro 'centro' y mitades 'mitadAncho' x 'mitadAlto'. Es un rayo contra el rectangulo agrandado por el radio,
+// con las esquinas redondeadas. Solo cuenta los contactos en los que el circulo se acerca a la cara
+func barridoCirculoRect(origen, desplazamiento pos, radio float32, centro pos, mitadAncho, mitadAlto float32) (contacto, bool) {
+	entrada := float32(math.Inf(-1))
+	salida := float32(math.Inf(1))
+	var normal pos
+
+	ejes := []struct {
+		origen, desplazamiento, min, max float32
+		normalMin, normalMax             pos
+	}{
+		{origen.x, desplazamiento.x, centro.x - mitadAncho - radio, centro.x + mitadAncho + radio, pos{-1, 0}, pos{1, 0}},
+		{origen.y, desplazamiento.y, centro.y - mitadAlto - radio, centro.y + mitadAlto + radio, pos{0, -1}, pos{0, 1}},
+	}
+
+	for _, eje := range ejes {
+		if eje.desplazamiento == 0 {
+			if eje.origen < eje.min || eje.origen > eje.max {
+				return contacto{}, false
+			}
+			continue
+		}
+
+		t1 := (eje.min - eje.origen) / eje.desplazamiento
+		t2 := (eje.max - eje.origen) / eje.desplazamiento
+		normalEntrada := eje.normalMin
+		if t1 > t2 {
+			t1, t2 = t2, t1
+			normalEntrada = eje.normalMax
+		}
+
+		if t1 > entrada {
+			entrada = t1
+			normal = normalEntrada
+		}
+		if t2 < salida {
+			salida = t2
+		}
+	}
+
+	if entrada > salida || salida <= 0 || entrada > 1 {
+		return contacto{}, false
+	}
+	// Si ya arranca metida la cara por la que entraria queda detras: la sacamos por la cara mas cercana
+	if entrada < 0 {
+		dx := origen.x - centro.x
+		dy := origen.y - centro.y
+		if mitadAncho-abs32(dx) < mitadAlto-abs32(dy) {
+			normal = pos{signo32(dx), 0}
+		} else {
+			normal = pos{0, signo32(dy)}
+		}
+		entrada = 0
+	}
+
+	// Si el punto de entrada cae en la esquina del rectangulo agrandado, el contacto real es contra el circulo de la esquina
+	punto := pos{origen.x + desplazamiento.x*entrada, origen.y + desplazamiento.y*entrada}
+	dx := punto.x - centro.x
+	dy := punto.y - centro.y
+	if abs32(dx) > mitadAncho && abs32(dy) > mitadAlto {
+		esquina := pos{centro.x + signo32(dx)*mitadAncho, centro.y + signo32(dy)*mitadAlto}
+
+		t, ok := rayoCirculo(origen, desplazamiento, esquina, radio)
+		if !ok || t > 1 {
+			return contacto{}, false
+		}
+
+		punto = pos{origen.x + desplazamiento.x*t, origen.y + desplazamiento.y*t}
+		normal = pos{punto.x - esquina.x, punto.y - esquina.y}
+		largo := float32(math.Sqrt(float64(normal.x*normal.x + normal.y*normal.y)))
+		if largo == 0 {
+			return contacto{}, false
+		}
+		normal = pos{normal.x / largo, normal.y / largo}
+		entrada = t
+	}
+
+	// Si la pelota ya se esta alejando de la cara no hay contacto
+	if desplazamiento.x*normal.x+desplazamiento.y*normal.y >= 0 {
+		return contacto{}, false
+	}
+
+	return contacto{t: entrada, normal: normal}, true
+}
+
+// Barrido de un circulo de radio 'radio' que parte de 'origen' y se desplaza 'desplazamiento' contra el circulo
+// quieto con centro 'centro' y radio 'radioOtro'. Solo cuenta los contactos en los que se acercan
+func barridoCirculoCirculo(origen, desplazamiento pos, radio float32, centro pos, radioOtro float32) (contacto, bool) {
+	t, ok := rayoCirculo(origen, desplazamiento, centro, radio+radioOtro)
+	if !ok || t > 1 {
+		return contacto{}, false
+	}
+
+	punto := pos{origen.x + desplazamiento.x*t, origen.y + desplazamiento.y*t}
+	normal := pos{punto.x - centro.x, punto.y - centro.y}
+	largo := float32(math.Sqrt(float64(normal.x*normal.x + normal.y*normal.y)))
+	if largo == 0 {
+		return contacto{}, false
+	}
+	normal = pos{normal.x / largo, normal.y / largo}
+
+	if desplazamiento.x*normal.x+desplazamiento.y*normal.y >= 0 {
+		return contacto{}, false
+	}
+	return contacto{t: t, normal: normal}, true
+}
+
+// Primer instante t >= 0 en el que el rayo origen + desplazamiento*t toca el circulo (0 si ya arranca adentro)
+func rayoCirculo(origen, desplazamiento, centro pos, radio float32) (float32, bool) {
+	mx := origen.x - centro.x
+	my := origen.y - centro.y
+
+	a := desplazamiento.x*desplazamiento.x + desplazamiento.y*desplazamiento.y
+	b := mx*desplazamiento.x + my*desplazamiento.y
+	c := mx*mx + my*my - radio*radio
+
+	if c <= 0 {
+		return 0, true
+	}
+	if a == 0 || b >= 0 {
+		return 0, false
+	}
+
+	discriminante := b*b - a*c
+	if discriminante < 0 {
+		return 0, false
+	}
+
+	return (-b - float32(math.Sqrt(float64(discriminante)))) / a, true
+}
+
+func abs32(valor float32) float32 {
+	if valor < 0 {
+		return -valor
+	}
+	return valor
+}
+
+func min32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max32(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func signo32(valor float32) float32 {
+	if valor < 0 {
+		return -1
+	}
+	return 1
+}
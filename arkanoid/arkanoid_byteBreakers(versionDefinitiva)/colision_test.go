@@ -0,0 +1,109 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// Diferencia maxima aceptada en las fracciones y normales calculadas
+const toleranciaColision = 1e-4
+
+func cerca(a, b float32) bool {
+	return math.Abs(float64(a-b)) <= toleranciaColision
+}
+
+func TestBarridoCirculoRect(t *testing.T) {
+	diagonal := float32(1 / math.Sqrt2)
+
+	casos := []struct {
+		nombre         string
+		origen         pos
+		desplazamiento pos
+		radio          float32
+		centro         pos
+		mitadAncho     float32
+		mitadAlto      float32
+		toca           bool
+		t              float32
+		normal         pos
+	}{
+		// En un tick la pelota pasaria de un lado al otro del ladrillo: el contacto es en la cara de abajo
+		{"pelota rapida contra ladrillo fino", pos{100, 140}, pos{0, -100}, 5, pos{100, 100}, 25, 2, true, 0.33, pos{0, 1}},
+		{"pelota rapida que pasa al costado", pos{140, 140}, pos{0, -100}, 5, pos{100, 100}, 25, 2, false, 0, pos{}},
+		// En diagonal contra la esquina: toca el circulo de la esquina, no el rectangulo agrandado
+		{"esquina", pos{20, 20}, pos{-20, -20}, 5, pos{0, 0}, 10, 10, true, (float32(math.Sqrt(200)) - 5) / float32(math.Sqrt(800)), pos{diagonal, diagonal}},
+		{"pasa cerca de la esquina sin tocarla", pos{20, 8}, pos{-12, 12}, 5, pos{0, 0}, 10, 10, false, 0, pos{}},
+		{"termina justo en la cara", pos{0, 25}, pos{0, -10}, 5, pos{0, 0}, 10, 10, true, 1, pos{0, 1}},
+		{"arranca justo en la cara", pos{0, 15}, pos{0, -10}, 5, pos{0, 0}, 10, 10, true, 0, pos{0, 1}},
+		{"cara de costado", pos{-30, 0}, pos{20, 0}, 5, pos{0, 0}, 10, 10, true, 0.75, pos{-1, 0}},
+		// Si ya arranca metida en el ladrillo sale por la cara hacia la que se mueve menos
+		{"arranca adentro acercandose", pos{0, 13}, pos{0, -10}, 5, pos{0, 0}, 10, 10, true, 0, pos{0, 1}},
+		{"arranca adentro alejandose", pos{0, 13}, pos{0, 10}, 5, pos{0, 0}, 10, 10, false, 0, pos{}},
+		{"se aleja de la cara", pos{0, 20}, pos{0, 10}, 5, pos{0, 0}, 10, 10, false, 0, pos{}},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			c, ok := barridoCirculoRect(caso.origen, caso.desplazamiento, caso.radio, caso.centro, caso.mitadAncho, caso.mitadAlto)
+			if ok != caso.toca {
+				t.Fatalf("toca = %v, se esperaba %v (contacto %+v)", ok, caso.toca, c)
+			}
+			if !ok {
+				return
+			}
+			if !cerca(c.t, caso.t) || !cerca(c.normal.x, caso.normal.x) || !cerca(c.normal.y, caso.normal.y) {
+				t.Errorf("contacto en t = %v con normal %v, se esperaba t = %v con normal %v", c.t, c.normal, caso.t, caso.normal)
+			}
+		})
+	}
+}
+
+func TestRayoCirculo(t *testing.T) {
+	casos := []struct {
+		nombre         string
+		origen         pos
+		desplazamiento pos
+		toca           bool
+		t              float32
+	}{
+		{"de frente", pos{0, 20}, pos{0, -20}, true, 0.75},
+		{"arranca adentro", pos{0, 2}, pos{0, 20}, true, 0},
+		{"se aleja", pos{0, 20}, pos{0, 20}, false, 0},
+		{"pasa al costado", pos{10, 20}, pos{0, -40}, false, 0},
+		{"quieto afuera", pos{0, 20}, pos{0, 0}, false, 0},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			tiempo, ok := rayoCirculo(caso.origen, caso.desplazamiento, pos{0, 0}, 5)
+			if ok != caso.toca || ok && !cerca(tiempo, caso.t) {
+				t.Errorf("rayoCirculo = %v, %v; se esperaba %v, %v", tiempo, ok, caso.t, caso.toca)
+			}
+		})
+	}
+}
+
+// En un mismo tick la pelota toca la cara de abajo de un ladrillo y despues la pared izquierda: tiene que
+// rebotar en las dos, golpear el ladrillo una vez y terminar donde la dejan los dos rebotes
+func TestBarridoDosCaras(t *testing.T) {
+	muro := []ladrillo{{pos: pos{100, 100}, ancho: 200, alto: 20, resist: 1}}
+	bola := pelota{pos: pos{30, 130}, radio: 5, vel_x: -40, vel_y: -40}
+
+	golpes := 0
+	bola.barrido(1/float32(ticksPorSegundo), obstaculos{muro: muro}, func(c contacto) {
+		if c.tipo != contactoLadrillo || c.indice != 0 {
+			t.Errorf("contacto inesperado %+v", c)
+		}
+		golpes++
+	})
+
+	if golpes != 1 {
+		t.Errorf("el ladrillo se golpeo %d veces", golpes)
+	}
+	if bola.vel_x != 40 || bola.vel_y != 40 {
+		t.Errorf("velocidad (%v, %v), se esperaba (40, 40)", bola.vel_x, bola.vel_y)
+	}
+	if !cerca(bola.pos.x, 20) || !cerca(bola.pos.y, 140) {
+		t.Errorf("la pelota termino en %v, se esperaba (20, 140)", bola.pos)
+	}
+}
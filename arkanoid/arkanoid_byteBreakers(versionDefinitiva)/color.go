@@ -0,0 +1,54 @@
+package main
+
+// ------------------------------------------------------------------------------------
+// -----------------------------------COLORES------------------------------------------
+// ------------------------------------------------------------------------------------
+
+// Formato de los pixeles de la ventana: en que byte de los 4 de cada pixel va cada canal
+type formatoPixel struct {
+	r, g, b, a int
+}
+
+// Formato de la ventana. Es el PIXELFORMAT_RGBA32 de SDL: rojo, verde, azul y alfa byte a byte en memoria
+// en cualquier maquina. (RGBA8888 en cambio es un entero de 32 bits con el rojo en los bits altos, que en
+// little endian queda como a,b,g,r en memoria y daba vuelta todos los colores)
+var formatoVentana = formatoPixel{r: 0, g: 1, b: 2, a: 3}
+
+// Metodo que escribe el color en los 4 bytes del pixel
+func (formato formatoPixel) escribir(pixel []byte, c color) {
+	pixel[formato.r] = c.r
+	pixel[formato.g] = c.g
+	pixel[formato.b] = c.b
+	pixel[formato.a] = c.a
+}
+
+// Metodo que lee el color de los 4 bytes del pixel
+func (formato formatoPixel) leer(pixel []byte) color {
+	return color{pixel[formato.r], pixel[formato.g], pixel[formato.b], pixel[formato.a]}
+}
+
+// Metodo que mezcla el color sobre el fondo segun su alfa (source-over, sin alfa premultiplicado).
+// Opaco tapa el fondo, transparente lo deja igual
+func (c color) sobre(fondo color) color {
+	switch c.a {
+	case 255:
+		return c
+	case 0:
+		return fondo
+	}
+
+	alfa := int(c.a)
+	alfaFondo := int(fondo.a) * (255 - alfa)
+	// Alfa del resultado multiplicado por 255
+	alfaTotal := alfa*255 + alfaFondo
+
+	canal := func(arriba, abajo byte) byte {
+		return byte((int(arriba)*alfa*255 + int(abajo)*alfaFondo + alfaTotal/2) / alfaTotal)
+	}
+	return color{
+		r: canal(c.r, fondo.r),
+		g: canal(c.g, fondo.g),
+		b: canal(c.b, fondo.b),
+		a: byte((alfaTotal + 127) / 255),
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ------------------------------------------------------------------------------------
+// -------------------------------CONFIGURACION----------------------------------------
+// ------------------------------------------------------------------------------------
+
+// Acciones del jugador que se pueden asignar a una tecla
+type accion int
+
+const (
+	accionIzquierda accion = iota
+	accionDerecha
+	accionLanzar
+	accionDisparar
+	accionPausa
+	cantidadAcciones
+)
+
+// Nombre de cada accion en el archivo y en el menu de opciones
+var nombresAcciones = [cantidadAcciones]string{"izquierda", "derecha", "lanzar", "disparar", "pausa"}
+
+// Teclas por defecto (con el nombre que les da SDL)
+var teclasPorDefecto = [cantidadAcciones]string{"Left", "Right", "Space", "Up", "P"}
+
+// Limites de los valores de la configuracion
+const (
+	minVelocidadBarra = 5
+	maxVelocidadBarra = 30
+	maxEscala         = 3
+	minSensibilidad   = 10 // Porcentaje del movimiento del mouse que se aplica a la barra
+	maxSensibilidad   = 400
+)
+
+// Configuracion del jugador. Se guarda en configuracion.txt, una linea por valor: "<clave> <valor>"
+//
+//	tecla.<accion> <nombre de la tecla>   -> tecla.izquierda Left
+//	velocidad <pixeles por fotograma de la barra>
+//	vidas <vidas al empezar la partida>
+//	escala <tamaño de la ventana: 1, 2 o 3 veces el original>
+//	mouse <sensibilidad del mouse en porcentaje: 100 mueve la barra lo mismo que el mouse>
+//	fuente <ruta del archivo .ttf de los textos>
+type configuracion struct {
+	teclas         [cantidadAcciones]string
+	velocidadBarra int
+	vidas          int
+	escala         int
+	sensibilidad   int
+	fuente         string
+}
+
+// Configuracion con la que arranca el juego si no hay archivo (o para los valores que faltan o son invalidos)
+func configuracionPorDefecto() configuracion {
+	return configuracion{
+		teclas:         teclasPorDefecto,
+		velocidadBarra: 15,
+		vidas:          3,
+		escala:         1,
+		sensibilidad:   100,
+		fuente:         "/usr/share/fonts/truetype/dejavu/DejaVuSans-Bold.ttf",
+	}
+}
+
+func rutaConfiguracion() (string, error) {
+	directorio, err := directorioJuego()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(directorio, "configuracion.txt"), nil
+}
+
+// Leemos la configuracion guardada. Si el archivo no existe usamos la configuracion por defecto; las lineas
+// con errores se ignoran (queda el valor por defecto) y se devuelven todas juntas en el error
+func leerConfiguracion() (configuracion, error) {
+	conf := configuracionPorDefecto()
+
+	ruta, err := rutaConfiguracion()
+	if err != nil {
+		return conf, err
+	}
+	datos, err := os.ReadFile(ruta)
+	if errors.Is(err, os.ErrNotExist) {
+		return conf, nil
+	}
+	if err != nil {
+		return conf, err
+	}
+
+	var errores []error
+	for numero, linea := range strings.Split(string(datos), "\n") {
+		linea = strings.TrimSpace(linea)
+		if linea == "" || strings.HasPrefix(linea, "#") {
+			continue
+		}
+		if err := conf.leerLinea(linea); err != nil {
+			errores = append(errores, fmt.Errorf("%s:%d: %w", ruta, numero+1, err))
+		}
+	}
+
+	// Dos acciones con la misma tecla: volvemos todas las teclas a las de por defecto
+	if err := conf.validarTeclas(); err != nil {
+		errores = append(errores, fmt.Errorf("%s: %w", ruta, err))
+		conf.teclas = teclasPorDefecto
+	}
+
+	return conf, errors.Join(errores...)
+}
+
+// Metodo que aplica una linea "<clave> <valor>" del archivo si el valor es valido
+func (conf *configuracion) leerLinea(linea string) error {
+	clave, valor, _ := strings.Cut(linea, " ")
+	valor = strings.TrimSpace(valor)
+	if valor == "" {
+		return fmt.Errorf("%q no tiene valor", clave)
+	}
+
+	if nombre, ok := strings.CutPrefix(clave, "tecla."); ok {
+		for accion, nombreAccion := range nombresAcciones {
+			if nombreAccion == nombre {
+				conf.teclas[accion] = valor
+				return nil
+			}
+		}
+		return fmt.Errorf("accion %q desconocida", nombre)
+	}
+
+	switch clave {
+	case "fuente":
+		conf.fuente = valor
+		return nil
+	case "velocidad", "vidas", "escala", "mouse":
+	default:
+		return fmt.Errorf("clave %q desconocida", clave)
+	}
+
+	numero, err := strconv.Atoi(valor)
+	if err != nil {
+		return fmt.Errorf("%s: %q no es un numero", clave, valor)
+	}
+	switch clave {
+	case "velocidad":
+		if numero < minVelocidadBarra || numero > maxVelocidadBarra {
+			return fmt.Errorf("velocidad %d fuera de rango (%d a %d)", numero, minVelocidadBarra, maxVelocidadBarra)
+		}
+		conf.velocidadBarra = numero
+	case "vidas":
+		if numero < 1 || numero > maxVidas {
+			return fmt.Errorf("vidas %d fuera de rango (1 a %d)", numero, maxVidas)
+		}
+		conf.vidas = numero
+	case "escala":
+		if numero < 1 || numero > maxEscala {
+			return fmt.Errorf("escala %d fuera de rango (1 a %d)", numero, maxEscala)
+		}
+		conf.escala = numero
+	case "mouse":
+		if numero < minSensibilidad || numero > maxSensibilidad {
+			return fmt.Errorf("mouse %d fuera de rango (%d a %d)", numero, minSensibilidad, maxSensibilidad)
+		}
+		conf.sensibilidad = numero
+	}
+	return nil
+}
+
+// Metodo que verifica que ninguna tecla este asignada a dos acciones
+func (conf *configuracion) validarTeclas() error {
+	for i := accion(0); i < cantidadAcciones; i++ {
+		for j := i + 1; j < cantidadAcciones; j++ {
+			if strings.EqualFold(conf.teclas[i], conf.teclas[j]) {
+				return fmt.Errorf("la tecla %q esta asignada a %s y a %s", conf.teclas[i], nombresAcciones[i], nombresAcciones[j])
+			}
+		}
+	}
+	return nil
+}
+
+// Guardamos la configuracion completa
+func guardarConfiguracion(conf configuracion) error {
+	ruta, err := rutaConfiguracion()
+	if err != nil {
+		return err
+	}
+
+	var datos strings.Builder
+	for accion, tecla := range conf.teclas {
+		fmt.Fprintf(&datos, "tecla.%s %s\n", nombresAcciones[accion], tecla)
+	}
+	fmt.Fprintf(&datos, "velocidad %d\n", conf.velocidadBarra)
+	fmt.Fprintf(&datos, "vidas %d\n", conf.vidas)
+	fmt.Fprintf(&datos, "escala %d\n", conf.escala)
+	fmt.Fprintf(&datos, "mouse %d\n", conf.sensibilidad)
+	fmt.Fprintf(&datos, "fuente %s\n", conf.fuente)
+	return escribirArchivoAtomico(ruta, []byte(datos.String()))
+}
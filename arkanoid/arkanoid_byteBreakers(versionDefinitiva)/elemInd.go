@@ -4,24 +4,28 @@ import (
 	"runtime"
 	"strconv"
 	"sync"
-
-	"github.com/veandco/go-sdl2/sdl"
 )
 
 const anchoVentana = 600
 const altoVentana = 800
 
+// Las velocidades (vel_x, vel_y) estan expresadas en pixeles por fotograma a esta frecuencia
+const frecuenciaBase = 60
+
+// Frecuencia por defecto de la simulacion (ticks por segundo)
+const ticksPorSegundo = 60
+
 type estadoJuego int
 
 const (
 	start estadoJuego = iota
 	play
 	loose
-	win
+	win     // Gano el ultimo nivel de la campaña
+	cleared // Supero un nivel y quedan mas en la campaña
+	pausa   // Pausada por el jugador, Step no avanza hasta reanudar
 )
 
-var state estadoJuego = start
-
 //-------------------------------------------------
 // ---------------------STRUCTS--------------------
 //-------------------------------------------------
@@ -49,7 +53,12 @@ type barra struct {
 	vida    int
 	score   int
 	pelotas []pelota
-	teclado []uint8
+	control InputSource // De donde sale la entrada (teclado, guion, bot)
+	entrada Input       // Entrada leida en el tick actual
+	atrapa  bool        // Poder atrapar: las pelotas se pegan al tocarla
+	laser   bool        // Poder laser
+
+	posAnterior pos // Posicion en el tick anterior (para interpolar el dibujo)
 }
 
 // Pelota
@@ -60,6 +69,12 @@ type pelota struct {
 	vel_y   float32
 	color   color
 	jugador *barra
+
+	pegada       bool    // Apoyada sobre la barra esperando el lanzamiento
+	desvioPegada float32 // Distancia horizontal al centro de la barra mientras esta pegada
+	tiempoPegada float32 // Segundos que faltan para que se lance sola
+
+	posAnterior pos // Posicion en el tick anterior (para interpolar el dibujo)
 }
 
 // Ladrillo
@@ -70,20 +85,23 @@ type ladrillo struct {
 	color    color
 	resist   int
 	extScore int
+	tipo     tipoLadrillo
+	vel_x    float32 // Solo los moviles
+	regenera float32 // Segundos que le faltan a un regenerable roto para volver
 }
 
 // ------------------------------------------------------------------------------------
 // ----------------------------------INTERFAZ-------------------------------------------
 // ------------------------------------------------------------------------------------
 
-// Interfaz structs con metodos Dibujar() -> ladrillo, pelota y barra
+// Interfaz structs con metodos Dibujar() -> ladrillo, pelota, barra, capsula, disparo, enemigo, jefe y proyectil
 type Dibujable interface {
 	Dibujar(ventana []byte)
 }
 
-// Interfaz structs con metodos Movimiento() -> pelota y barra
+// Interfaz structs con metodos Movimiento() -> pelota, barra, capsula, disparo, enemigo, jefe y proyectil. dt son los segundos que avanza el tick
 type Movible interface {
-	Movimiento()
+	Movimiento(dt float32)
 }
 
 // El 'elem' seria algun ladrillo, pelota o barra y dentro de la funcion aplicamos el metodo Dibujar() al respectivo elem
@@ -92,8 +110,8 @@ func llamarDibujar(elem Dibujable, ventana []byte) {
 }
 
 // El 'elem' seria algun pelota o barra y dentro de la funcion llamamos al metodo Movimiento() para dicho struct
-func llamarMovimiento(elem Movible) {
-	elem.Movimiento()
+func llamarMovimiento(elem Movible, dt float32) {
+	elem.Movimiento(dt)
 }
 
 // ------------------------------------------------------------------------------------
@@ -110,13 +128,14 @@ func (bloque *ladrillo) Dibujar(ventana []byte) {
 	numCPUs := runtime.NumCPU()
 	wg.Add(numCPUs)
 	arrLadrillo := make([]byte, bloque.alto*bloque.ancho)
-	pedazoLadrillo := len(arrLadrillo) / numCPUs
+	// Redondeamos para arriba asi los pedazos cubren todo el ladrillo con cualquier cantidad de CPUs
+	pedazoLadrillo := (len(arrLadrillo) + numCPUs - 1) / numCPUs
 
 	for i := 0; i < numCPUs; i++ {
 		go func(i int) {
 			defer wg.Done()
 			inicio := i * pedazoLadrillo
-			fin := inicio + pedazoLadrillo + 4
+			fin := inicio + pedazoLadrillo
 
 			for j := inicio; j < fin; j++ {
 				if j < len(arrLadrillo) {
@@ -145,7 +164,8 @@ func (barra *barra) Dibujar(ventana []byte) {
 
 	arrBarra := make([]byte, barra.ancho*barra.alto)
 
-	pedazoBarra := len(arrBarra) / numCPUs
+	// Redondeamos para arriba asi los pedazos cubren toda la barra con cualquier cantidad de CPUs
+	pedazoBarra := (len(arrBarra) + numCPUs - 1) / numCPUs
 
 	for i := 0; i < numCPUs; i++ {
 
@@ -154,7 +174,10 @@ func (barra *barra) Dibujar(ventana []byte) {
 			defer wg.Done()
 
 			inicio := i * pedazoBarra
-			fin := inicio + pedazoBarra + 4
+			fin := inicio + pedazoBarra
+			if fin > len(arrBarra) {
+				fin = len(arrBarra)
+			}
 
 			for j := inicio; j < fin; j++ {
 
@@ -166,9 +189,12 @@ func (barra *barra) Dibujar(ventana []byte) {
 		}(i)
 	}
 	wg.Wait()
+}
 
-	graficarVida(*barra, ventana)
-	graficarPuntaje(*barra, ventana, 3, 3, pos{float32(anchoVentana) + 225, float32(altoVentana) - 20}, color{255, 255, 255, 255})
+// Puntaje alineado a la derecha: cada digito ocupa 6 columnas de 3 pixeles y la coordenada es el centro del primero
+func centroPuntaje(score int) pos {
+	digitos := len(strconv.Itoa(score))
+	return pos{float32(anchoVentana) - 15 - float32(digitos*6*3) + float32(5*3)/2, float32(altoVentana) - 20}
 }
 
 func (pelota *pelota) Dibujar(ventana []byte) {
@@ -184,168 +210,47 @@ func (pelota *pelota) Dibujar(ventana []byte) {
 	}
 }
 
-// Metodo que mueve la barra a los laterales
-func (barra *barra) Movimiento() {
-	if barra.teclado[sdl.SCANCODE_LEFT] != 0 {
-		if barra.pos.x-float32(barra.ancho)/2 > 0 {
-			barra.pos.x -= barra.vel_x
-		}
-
-	} else if barra.teclado[sdl.SCANCODE_RIGHT] != 0 {
-		if barra.pos.x+float32(barra.ancho)/2 < float32(anchoVentana) {
-			barra.pos.x += barra.vel_x
-		}
+// Metodo que lee la fuente de entrada de la barra una unica vez por tick de dt segundos
+func (barra *barra) leerControl(dt float32) {
+	barra.entrada = Input{}
+	if barra.control != nil {
+		barra.entrada = barra.control.Leer(barra.vel_x, dt)
 	}
+	barra.entrada.analogico = cuantizarAnalogico(barra.entrada.analogico)
 }
 
-// Metodo movimiento pelotita
-func (pelota *pelota) Movimiento() {
-
-	pelota.pos.x += pelota.vel_x
-	pelota.pos.y += pelota.vel_y
-
-	if pelota.pos.y-pelota.radio <= 0 {
-		pelota.vel_y = -pelota.vel_y
-	}
-
-	if pelota.pos.x-pelota.radio <= 0 || pelota.pos.x+pelota.radio >= float32(anchoVentana) {
-		pelota.vel_x = -pelota.vel_x
+// Metodo que mueve la barra a los laterales segun la entrada del tick actual. El control analogico la
+// lleva directo a su posicion, sin salirse de la ventana; si no hay movimiento analogico manda el teclado
+func (barra *barra) Movimiento(dt float32) {
+	if barra.entrada.analogico != 0 {
+		mitad := float32(barra.ancho) / 2
+		barra.pos.x = limitarFloat(barra.pos.x+barra.entrada.analogico, mitad, float32(anchoVentana)-mitad)
+		return
 	}
 
-	if pelota.pos.y >= float32(altoVentana) {
+	desplazamiento := barra.vel_x * dt * frecuenciaBase
 
-		if len(pelota.jugador.pelotas) > 1 {
-			for i, v := range pelota.jugador.pelotas {
-				if v.pos == *&pelota.pos {
-					if i > 0 && i < len(pelota.jugador.pelotas) {
-						pelota.jugador.pelotas = append(pelota.jugador.pelotas[:i], pelota.jugador.pelotas[i+1:]...)
-					} else if i == 0 {
-						pelota.jugador.pelotas = append(pelota.jugador.pelotas[1:])
-					}
-
-				}
-			}
-		} else {
-			pelota.pos.x = float32(anchoVentana) / 2
-			pelota.pos.y = float32(altoVentana)/2 + 100
-			pelota.vel_x = 0
-			pelota.vel_y = 10
-			state = start
-			pelota.jugador.pos.x = float32(anchoVentana) / 2
-			pelota.jugador.pos.y = float32(altoVentana) - 50
-			pelota.jugador.vida--
-
-			if pelota.jugador.vida == 0 {
-				state = loose
-			}
+	if barra.entrada.izquierda {
+		if barra.pos.x-float32(barra.ancho)/2 > 0 {
+			barra.pos.x -= desplazamiento
 		}
 
-	}
-
-	if pelota.pos.y+pelota.radio >= pelota.jugador.pos.y-float32(pelota.jugador.alto)/2 && pelota.pos.y+pelota.radio <= pelota.jugador.pos.y+float32(pelota.jugador.alto)/2 {
-		if pelota.pos.x >= pelota.jugador.pos.x-float32(pelota.jugador.ancho)/2 && pelota.pos.x <= pelota.jugador.pos.x+float32(pelota.jugador.ancho)/2 {
-			velocidades_x := []int{-11, -9, -7, -5, -3, -1, 1, 3, 5, 7, 9, 11}
-			configuracion_velocidad(pelota, pelota.jugador, velocidades_x)
+	} else if barra.entrada.derecha {
+		if barra.pos.x+float32(barra.ancho)/2 < float32(anchoVentana) {
+			barra.pos.x += desplazamiento
 		}
 	}
 }
 
-// Metodo pelota para romper ladrillo al impactar la pelota
-func (bola *pelota) impactoLadrillo(ladrillo *ladrillo, ventana []byte, resistenciaColor map[int]color) {
-
-	var refinadoImpacto float32 = 5.0
-
-	if ladrillo.resist > 0 {
-		// Si la pelota golpea la cara inferior o superior del ladrillo
-		if bola.pos.x >= ladrillo.pos.x-float32(ladrillo.ancho)/2 && bola.pos.x <= ladrillo.pos.x+float32(ladrillo.ancho)/2 {
-			// Si golpea la cara inferior
-			if bola.pos.y-bola.radio-refinadoImpacto <= ladrillo.pos.y+float32(ladrillo.alto)/2 && bola.pos.y-bola.radio >= ladrillo.pos.y {
-				bola.vel_y = -bola.vel_y
-				bola.pos.y = ladrillo.pos.y + float32(ladrillo.alto)/2 + bola.radio
-				ladrillo.resist--
-				ladrillo.color = resistenciaColor[ladrillo.resist]
-				efecto_puntaje(bola, ladrillo)
-				if ladrillo.resist == 0 {
-					bola.jugador.score += ladrillo.extScore
-				}
-
-			}
-			// Si golpea la cara superior
-			if bola.pos.y+bola.radio+refinadoImpacto >= ladrillo.pos.y-float32(ladrillo.alto)/2 && bola.pos.y+bola.radio <= ladrillo.pos.y {
-				bola.vel_y = -bola.vel_y
-				bola.pos.y = ladrillo.pos.y - float32(ladrillo.alto)/2 - bola.radio
-				ladrillo.resist--
-				ladrillo.color = resistenciaColor[ladrillo.resist]
-				efecto_puntaje(bola, ladrillo)
-				if ladrillo.resist == 0 {
-					bola.jugador.score += ladrillo.extScore
-				}
-			}
-
-		}
-		// Si la pelota golpea la cara izquierda o derecha del ladrillo
-		if bola.pos.y >= ladrillo.pos.y-float32(ladrillo.alto)/2 && bola.pos.y <= ladrillo.pos.y+float32(ladrillo.alto)/2 {
-			// Si golpea la cara izquierda
-			if bola.pos.x+bola.radio+refinadoImpacto >= ladrillo.pos.x-float32(ladrillo.ancho)/2 && bola.pos.x+bola.radio <= ladrillo.pos.x {
-				bola.vel_x = -bola.vel_x
-				bola.pos.x = ladrillo.pos.x - float32(ladrillo.ancho)/2 - bola.radio
-				ladrillo.resist--
-				ladrillo.color = resistenciaColor[ladrillo.resist]
-				efecto_puntaje(bola, ladrillo)
-				if ladrillo.resist == 0 {
-					bola.jugador.score += ladrillo.extScore
-				}
-			}
-			// Si golpea la cara derecha
-			if bola.pos.x-bola.radio-refinadoImpacto <= ladrillo.pos.x+float32(ladrillo.ancho)/2 && bola.pos.x-bola.radio >= ladrillo.pos.x {
-				bola.vel_x = -bola.vel_x
-				bola.pos.x = ladrillo.pos.x + float32(ladrillo.ancho)/2 + bola.radio
-				ladrillo.resist--
-				ladrillo.color = resistenciaColor[ladrillo.resist]
-				efecto_puntaje(bola, ladrillo)
-				if ladrillo.resist == 0 {
-					bola.jugador.score += ladrillo.extScore
-				}
-			}
-		}
-
-	}
+// Metodo movimiento pelotita: rebota en las paredes y en la barra (los ladrillos los resuelve el juego con barrido())
+func (pelota *pelota) Movimiento(dt float32) {
+	pelota.barrido(dt, obstaculos{}, nil)
 }
 
 // ---------------------------------------------------------------------------------------------
 // -------------------------------------FUNCIONES-----------------------------------------------
 // ---------------------------------------------------------------------------------------------
 
-func efecto_puntaje(bola *pelota, bloque *ladrillo) {
-	score_newball := 100
-	canal_Pelota := make(chan int)
-	nueva_pelota := pelota{
-		pos{float32(anchoVentana) / 2, float32(altoVentana)/2 + 100},
-		bola.radio,
-		0,
-		-10,
-		color{0, 255, 255, 255}, // BLANCO
-		bola.jugador,
-	}
-
-	go func() {
-		if bola.jugador.score != 0 && bola.jugador.score%score_newball == 0 && bloque.resist == 0 {
-			bola.jugador.pelotas = append(bola.jugador.pelotas, nueva_pelota)
-			canal_Pelota <- 1
-		}
-		canal_Pelota <- 0
-		close(canal_Pelota)
-	}()
-
-	go func() {
-		var reduccionBarra int = 5
-		aux := <-canal_Pelota
-		if aux == 1 {
-			bola.jugador.ancho -= reduccionBarra
-		}
-	}()
-}
-
 // Funcion para graficar el score del jugador
 func graficarPuntaje(barra barra, ventana []byte, ancho, alto int, coordenada pos, color color) {
 
@@ -514,7 +419,7 @@ func graficarVida(barra barra, ventana []byte) {
 				if valor == 1 {
 					for y := startY; y < startY+3; y++ {
 						for x := startX; x < startX+3; x++ {
-							colorear(pos{float32(x), float32(y)}, color{0, 0, 0, 255}, ventana)
+							colorear(pos{float32(x), float32(y)}, color{255, 0, 0, 255}, ventana) // ROJO
 						}
 					}
 				}
@@ -538,7 +443,8 @@ func configuracion_velocidad(pelota *pelota, jugador *barra, velocidades_x []int
 	var segmento float32 = float32(jugador.ancho) / float32(len(velocidades_x))
 
 	for indice, velocidad := range velocidades_x {
-		if pelota.pos.x <= jugador.pos.x-float32(jugador.ancho)/2+segmento*(float32(indice+1)) {
+		// Si la pelota toca la esquina derecha queda fuera de la barra: usamos el ultimo segmento
+		if pelota.pos.x <= jugador.pos.x-float32(jugador.ancho)/2+segmento*(float32(indice+1)) || indice == len(velocidades_x)-1 {
 			pelota.vel_x = float32(velocidad)
 			pelota.vel_y = -pelota.vel_y
 			pelota.pos.y = jugador.pos.y - float32(jugador.alto)/2 - pelota.radio
@@ -547,102 +453,57 @@ func configuracion_velocidad(pelota *pelota, jugador *barra, velocidades_x []int
 	}
 }
 
-// Colorear pixel ventana
+// Colorear pixel ventana mezclando el color con lo que ya habia segun su alfa. Truncamos x e y por separado
+// (si no, una y con decimales corre el pixel de fila) y descartamos lo que cae fuera de la ventana
+// en vez de pasarlo a la fila siguiente
 func colorear(pos pos, c color, ventana []byte) {
-	index := (pos.y*float32(anchoVentana) + pos.x) * 4
-
-	if int(index) < len(ventana)-4 && index >= 0 {
-		ventana[int(index)] = c.r
-		ventana[int(index+1)] = c.g
-		ventana[int(index+2)] = c.b
-		ventana[int(index+3)] = c.a
+	if pos.x < 0 || pos.y < 0 || pos.x >= float32(anchoVentana) || pos.y >= float32(altoVentana) {
+		return
 	}
-}
-
-// Limpieza ventana en negro
-func limpieza(ventana []byte) {
-
-	numGorrutinas := runtime.NumCPU()
-
-	var wg sync.WaitGroup
-
-	wg.Add(numGorrutinas)
-
-	porcionVentana := len(ventana) / numGorrutinas
-
-	for i := 0; i < numGorrutinas; i++ {
+	index := (int(pos.y)*int(anchoVentana) + int(pos.x)) * 4
+	pixel := ventana[index : index+4]
 
-		go func(i int) {
-			defer wg.Done()
-			start := i * porcionVentana
-			end := start + porcionVentana - 1
-
-			for j := start; j < end; j++ {
-				x := j % int(anchoVentana)
-				y := (j - x) / int(altoVentana)
-				colorear(pos{float32(x), float32(y)}, color{0, 0, 0, 0}, ventana)
-			}
-		}(i)
+	if c.a == 255 {
+		formatoVentana.escribir(pixel, c)
+		return
 	}
-	wg.Wait()
+	formatoVentana.escribir(pixel, c.sobre(formatoVentana.leer(pixel)))
 }
 
-// Diagramamos muro con todos los ladrillos, sus coordenadas y sus resistencias
-func diagramar_mapa(coordenada pos, ancho int, alto int, ventana []byte) ([]ladrillo, map[int]color) {
-
-	var ladrillos = []byte{
-		0, 1, 2, 3, 4, 5, 0, 0, 0,
-		0, 0, 0, 0, 0, 0, 0, 0, 0,
-		0, 0, 0, 0, 0, 0, 0, 0, 0,
-		0, 0, 0, 0, 0, 0, 0, 0, 0,
-		0, 0, 0, 0, 0, 0, 0, 0, 0,
-		0, 0, 0, 0, 0, 0, 0, 0, 0,
-		1, 1, 1, 1, 1, 0, 0, 0, 0,
-		1, 1, 1, 1, 1, 0, 0, 0, 0,
-		1, 1, 1, 1, 1, 0, 0, 0, 0,
-		1, 1, 1, 1, 1, 0, 0, 0, 0,
-		1, 1, 1, 1, 1, 0, 0, 0, 0,
-		1, 1, 1, 1, 1, 0, 5, 5, 0,
-		1, 1, 1, 1, 1, 5, 5, 0, 5,
-		1, 1, 1, 1, 1, 5, 5, 5, 5,
-		1, 1, 1, 1, 1, 5, 5, 5, 5,
-		1, 1, 1, 1, 1, 5, 5, 5, 5,
-		1, 1, 1, 1, 1, 0, 0, 0, 0,
-	}
-
-	resistenciaColor := make(map[int]color)
-	resistenciaColor[0] = color{0, 0, 0, 0}       // NEGRO
-	resistenciaColor[1] = color{0, 152, 152, 255} // ROJO MUY CLARO
-	resistenciaColor[2] = color{0, 84, 84, 255}   // ROJO CLARO
-	resistenciaColor[3] = color{0, 0, 0, 255}     // ROJO PURO
-	resistenciaColor[4] = color{0, 0, 0, 190}     // ROJO OSCURO
-	resistenciaColor[5] = color{0, 0, 0, 120}     // ROJO MUY OSCURO
-
-	muro := make([]ladrillo, 9*17) // Ancho*alto muro ladrillos
-	startX := int(coordenada.x) - (ancho*9)/2 + ancho/2
-	startY := int(coordenada.y) - (alto*17)/2 + alto/2
-
-	// Cada ladrillo lo construimos con gorrutinas distintas
-	var wg sync.WaitGroup
-	var mutex = &sync.Mutex{}
-
-	for indice, value := range ladrillos {
-		// Por cada gorrutina creada creamos 1 gorrutina adicional q debe finalizarse para seguir con el hilo principal
-		wg.Add(1)
-		go func(indice int, value byte) {
-			defer wg.Done()
-
-			x := startX + (indice%9)*(ancho+1)
-			y := startY + (indice/9)*(alto+1)
-
-			ladrillo := ladrillo{pos{float32(x), float32(y)}, ancho, alto, resistenciaColor[int(value)], int(value), 10}
-
-			mutex.Lock()
-			muro = append(muro, ladrillo)
-			mutex.Unlock()
-		}(indice, value)
+// Diagramamos muro con todos los ladrillos del nivel, sus coordenadas y sus resistencias
+func diagramar_mapa(nivel *nivel, indiceNivel int) ([]ladrillo, map[int]color) {
+
+	ancho := nivel.anchoLadrillo
+	alto := nivel.altoLadrillo
+	resistenciaColor := nivel.colores
+
+	muro := make([]ladrillo, nivel.ancho*nivel.alto) // Ancho*alto muro ladrillos
+	startX := int(nivel.origen.x) - (ancho*nivel.ancho)/2 + ancho/2
+	startY := int(nivel.origen.y) - (alto*nivel.alto)/2 + alto/2
+
+	// Cada ladrillo va en su indice, asi el orden del muro es siempre el del nivel (las repeticiones dependen de eso)
+	for indice, value := range nivel.resistencias {
+		x := startX + (indice%nivel.ancho)*(ancho+1)
+		y := startY + (indice/nivel.ancho)*(alto+1)
+
+		muro[indice] = ladrillo{pos: pos{float32(x), float32(y)}, ancho: ancho, alto: alto, color: resistenciaColor[int(value)], resist: int(value), extScore: 10}
+
+		// Ladrillos especiales: resistencia, puntaje y color segun su tipo
+		if tipo := nivel.tipos[indice]; tipo != ladrilloNormal {
+			muro[indice].tipo = tipo
+			muro[indice].color = nivel.coloresTipo[tipo]
+			muro[indice].resist = tiposLadrillo[tipo].resist
+			muro[indice].extScore = tiposLadrillo[tipo].extScore
+
+			switch tipo {
+			case ladrilloPlata:
+				muro[indice].resist = resistenciaPlata(indiceNivel)
+				muro[indice].extScore = puntajePlata(indiceNivel)
+			case ladrilloMovil:
+				muro[indice].vel_x = velocidadMovil
+			}
+		}
 	}
-	wg.Wait()
 
 	return muro, resistenciaColor
 }
@@ -657,65 +518,27 @@ func replicaMuro(muro []ladrillo) []ladrillo {
 	return copiaMuro
 }
 
-// Grafica de los ladrillos del muro
-func graficarLadrillos(muro []ladrillo, pixelesVentana []byte) {
-	var dl sync.WaitGroup
-	numCPUs := runtime.NumCPU()
-	dl.Add(numCPUs)
-	pedazoMuro := len(muro)/numCPUs + 1
-
-	for cpu := 0; cpu < numCPUs; cpu++ {
-		go func(cpu int) {
-			defer dl.Done()
-			start := cpu * pedazoMuro
-			fin := start + pedazoMuro
-
-			for i := start; i < fin; i++ {
-				if i < len(muro) {
-					llamarDibujar(&muro[i], pixelesVentana)
-				}
-			}
-		}(cpu)
-	}
-	dl.Wait()
-
-	contador := 0
-	for _, ladrillo := range muro {
-		negro := color{0, 0, 0, 0}
-		if ladrillo.color == negro {
-			contador++
-			if contador == len(muro) {
-				state = win
-			}
-		}
-	}
-}
+// Copia del jugador y sus pelotas en la posicion intermedia entre el tick anterior y el actual (alpha entre 0 y 1)
+func interpolarJugador(jugador barra, alpha float32) barra {
+	jugador.pos = interpolarPos(jugador.posAnterior, jugador.pos, alpha)
 
-// Grafica de las pelotas
-func graficarPelotas(jugador barra, pixelesVentana []byte) {
-	var dp sync.WaitGroup
-	dp.Add(len(jugador.pelotas))
-	for i := 0; i < len(jugador.pelotas); i++ {
-		go func(i int) {
-			defer dp.Done()
-			llamarDibujar(&jugador.pelotas[i], pixelesVentana)
-		}(i)
+	pelotas := make([]pelota, len(jugador.pelotas))
+	for i, bola := range jugador.pelotas {
+		bola.pos = interpolarPos(bola.posAnterior, bola.pos, alpha)
+		pelotas[i] = bola
 	}
-	dp.Wait()
+	jugador.pelotas = pelotas
+
+	return jugador
 }
 
-// Movimiento de las pelotas
-func movimientoPelotas(jugador barra) {
-	for i := 0; i < len(jugador.pelotas); i++ {
-		go llamarMovimiento(&jugador.pelotas[i])
-	}
+func interpolarPos(desde, hasta pos, alpha float32) pos {
+	return pos{desde.x + (hasta.x-desde.x)*alpha, desde.y + (hasta.y-desde.y)*alpha}
 }
 
-// Verifica el estado de todos los ladrillos del muro individualmente si c/u de las pelotas las golpeo o no
-func estadoLadrillos(jugador barra, muro []ladrillo, pixelesVentana []byte, resistenciaColor map[int]color) {
-	for i, _ := range muro {
-		for j := 0; j < len(jugador.pelotas); j++ {
-			jugador.pelotas[j].impactoLadrillo(&muro[i], pixelesVentana, resistenciaColor)
-		}
-	}
+// Impacto de una pelota contra un ladrillo, un enemigo o el jefe, detectado durante el tick y aplicado al final por el juego
+type impacto struct {
+	pelota int // Indice en jugador.pelotas
+	tipo   tipoContacto
+	indice int // Indice en el muro o en los enemigos segun el tipo
 }
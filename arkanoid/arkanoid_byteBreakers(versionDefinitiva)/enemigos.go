@@ -0,0 +1,206 @@
+package main
+
+import "math"
+
+// ------------------------------------------------------------------------------------
+// ----------------------------------ENEMIGOS------------------------------------------
+// ------------------------------------------------------------------------------------
+
+// Forma en la que se mueve el enemigo mientras baja
+type patronEnemigo int
+
+const (
+	patronCaida  patronEnemigo = iota // Baja en diagonal rebotando en las paredes
+	patronZigzag                      // Baja oscilando de un lado a otro
+	patronOrbita                      // Baja girando en circulos
+	cantidadPatrones
+)
+
+// Reglas de los enemigos
+const (
+	maxEnemigos        = 3 // Enemigos en pantalla a la vez
+	intervaloEnemigos  = 5 // Segundos entre la salida de dos enemigos
+	velocidadEnemigo   = 1 // Pixeles por fotograma que baja el centro del enemigo
+	radioEnemigo       = 8
+	amplitudEnemigo    = 60  // Pixeles que se aleja del centro en el zigzag y la orbita
+	periodoEnemigo     = 3   // Segundos de una oscilacion (o vuelta) completa
+	puntosEnemigo      = 100 // Puntos por destruir un enemigo
+	anchoPuerta        = 40
+	altoPuerta         = 6
+	segundosPuertaAbre = 0.5 // Segundos que la puerta se ve abierta antes y despues de soltar un enemigo
+)
+
+// Puertas del borde superior por donde salen los enemigos (coordenada x del centro)
+var puertasEnemigos = []float32{float32(anchoVentana) / 4, float32(anchoVentana) * 3 / 4}
+
+// Enemigo que entra por una puerta y baja por el campo hasta que lo destruyen o sale por abajo
+type enemigo struct {
+	pos       pos
+	centro    pos // Punto alrededor del cual oscila (baja a velocidad constante)
+	radio     float32
+	vel_x     float32
+	vel_y     float32
+	color     color
+	patron    patronEnemigo
+	fase      float32 // Segundos desde que salio
+	destruido bool    // Lo golpeo una pelota en este tick (se quita al moverlos)
+}
+
+// Metodo para dibujar el enemigo: un rombo
+func (enemigo *enemigo) Dibujar(ventana []byte) {
+	for y := -enemigo.radio; y < enemigo.radio; y++ {
+		for x := -enemigo.radio; x < enemigo.radio; x++ {
+			if abs32(x)+abs32(y) < enemigo.radio {
+				colorear(pos{enemigo.pos.x + x, enemigo.pos.y + y}, enemigo.color, ventana)
+			}
+		}
+	}
+}
+
+// Metodo que mueve el enemigo segun su patron
+func (enemigo *enemigo) Movimiento(dt float32) {
+	enemigo.fase += dt
+	enemigo.centro.y += enemigo.vel_y * dt * frecuenciaBase
+	angulo := 2 * math.Pi * float64(enemigo.fase) / periodoEnemigo
+
+	switch enemigo.patron {
+	case patronCaida:
+		enemigo.centro.x += enemigo.vel_x * dt * frecuenciaBase
+		if enemigo.centro.x-enemigo.radio < 0 || enemigo.centro.x+enemigo.radio > float32(anchoVentana) {
+			enemigo.vel_x = -enemigo.vel_x
+			enemigo.centro.x = limitarFloat(enemigo.centro.x, enemigo.radio, float32(anchoVentana)-enemigo.radio)
+		}
+		enemigo.pos = enemigo.centro
+
+	case patronZigzag:
+		enemigo.pos.x = enemigo.centro.x + amplitudEnemigo*float32(math.Sin(angulo))
+		enemigo.pos.y = enemigo.centro.y
+
+	case patronOrbita:
+		// Arranca arriba del centro para que salga de la puerta sin saltar
+		enemigo.pos.x = enemigo.centro.x + amplitudEnemigo/2*float32(math.Sin(angulo))
+		enemigo.pos.y = enemigo.centro.y - amplitudEnemigo/2*float32(math.Cos(angulo))
+	}
+}
+
+// Cuando se cumple el intervalo sale un enemigo nuevo por una puerta al azar (con el azar de la partida)
+func (juego *Game) soltarEnemigo() {
+	// En los niveles con jefe no salen enemigos
+	if juego.jefe != nil {
+		return
+	}
+
+	juego.esperaEnemigo -= juego.dt
+	if juego.esperaEnemigo > 0 {
+		return
+	}
+	juego.esperaEnemigo = intervaloEnemigos
+
+	if len(juego.enemigos) >= maxEnemigos {
+		return
+	}
+
+	puerta := juego.azar.Intn(len(puertasEnemigos))
+	patron := patronEnemigo(juego.azar.Intn(int(cantidadPatrones)))
+	vel_x := float32(velocidadEnemigo)
+	if juego.azar.Intn(2) == 0 {
+		vel_x = -vel_x
+	}
+
+	salida := pos{puertasEnemigos[puerta], altoPuerta + radioEnemigo}
+	juego.enemigos = append(juego.enemigos, enemigo{
+		pos:    salida,
+		centro: salida,
+		radio:  radioEnemigo,
+		vel_x:  vel_x,
+		vel_y:  velocidadEnemigo,
+		color:  color{255, 200, 0, 255}, // AMARILLO
+		patron: patron,
+	})
+	juego.puertaAbierta = puerta
+	juego.tiempoPuerta = segundosPuertaAbre
+}
+
+// Quitamos los que destruyeron las pelotas, movemos el resto y resolvemos sus choques con la barra y el laser,
+// que tambien los destruyen. Los que salen por abajo desaparecen sin dar puntos
+func (juego *Game) actualizarEnemigos() {
+	if juego.tiempoPuerta > 0 {
+		juego.tiempoPuerta -= juego.dt
+	}
+	juego.soltarEnemigo()
+
+	jugador := &juego.jugador
+
+	enJuego := juego.enemigos[:0]
+	for _, enemigo := range juego.enemigos {
+		if enemigo.destruido {
+			continue
+		}
+		llamarMovimiento(&enemigo, juego.dt)
+
+		if enemigo.chocaBarra(jugador) || juego.chocaDisparo(enemigo) {
+			juego.sumarPuntaje(puntosEnemigo)
+			continue
+		}
+		if enemigo.pos.y-enemigo.radio < float32(altoVentana) {
+			enJuego = append(enJuego, enemigo)
+		}
+	}
+	juego.enemigos = enJuego
+}
+
+// Una pelota golpeo al enemigo: sumamos sus puntos una sola vez aunque lo golpeen varias en el mismo tick
+func (juego *Game) destruirEnemigo(indice int) {
+	enemigo := &juego.enemigos[indice]
+	if enemigo.destruido {
+		return
+	}
+	enemigo.destruido = true
+	juego.sumarPuntaje(puntosEnemigo)
+}
+
+// Metodo que devuelve si el enemigo toca la barra
+func (enemigo *enemigo) chocaBarra(jugador *barra) bool {
+	return abs32(enemigo.pos.x-jugador.pos.x) <= float32(jugador.ancho)/2+enemigo.radio &&
+		abs32(enemigo.pos.y-jugador.pos.y) <= float32(jugador.alto)/2+enemigo.radio
+}
+
+// Si algun disparo del laser toca al enemigo lo quitamos y devolvemos true
+func (juego *Game) chocaDisparo(enemigo enemigo) bool {
+	for i, disparo := range juego.disparos {
+		if abs32(enemigo.pos.x-disparo.pos.x) <= float32(disparo.ancho)/2+enemigo.radio &&
+			abs32(enemigo.pos.y-disparo.pos.y) <= float32(disparo.alto)/2+enemigo.radio {
+			juego.disparos = append(juego.disparos[:i], juego.disparos[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Quitamos los enemigos y volvemos a esperar el intervalo completo (al perder una vida o cambiar de nivel)
+func (juego *Game) cancelarEnemigos() {
+	juego.enemigos = nil
+	juego.esperaEnemigo = intervaloEnemigos
+	juego.tiempoPuerta = 0
+}
+
+// Metodo que indica el color de la puerta: amarilla si acaba de soltar un enemigo
+func (juego *Game) colorPuerta(indice int) color {
+	if indice == juego.puertaAbierta && juego.tiempoPuerta > 0 {
+		return color{255, 200, 0, 255} // AMARILLO
+	}
+	return color{120, 120, 120, 255} // GRIS
+}
+
+// Grafica la puerta centrada en x en el borde superior
+func graficarPuerta(x float32, c color, ventana []byte) {
+	for y := 0; y < altoPuerta; y++ {
+		for i := 0; i < anchoPuerta; i++ {
+			colorear(pos{x - anchoPuerta/2 + float32(i), float32(y)}, c, ventana)
+		}
+	}
+}
+
+func limitarFloat(valor, minimo, maximo float32) float32 {
+	return max32(minimo, min32(valor, maximo))
+}
@@ -0,0 +1,82 @@
+package main
+
+import "math"
+
+// ------------------------------------------------------------------------------------
+// -----------------------------------ENTRADA------------------------------------------
+// ------------------------------------------------------------------------------------
+
+// Entrada del jugador para un tick de la simulacion
+type Input struct {
+	izquierda bool
+	derecha   bool
+	lanzar    bool
+	disparar  bool    // Dispara el laser si la barra lo tiene
+	analogico float32 // Pixeles que un control analogico (mouse, palanca del mando) mueve la barra en este tick
+}
+
+// Resolucion del movimiento analogico: se redondea a octavos de pixel para poder grabarlo exacto
+const pasosAnalogico = 8
+
+// Interfaz de las fuentes de entrada que controlan la barra -> teclado, mouse, mando, guion, bot.
+// velocidad (pixeles por fotograma) y dt (segundos del tick) son los de la barra en este tick, para las
+// fuentes analogicas que la mueven en proporcion a su velocidad
+type InputSource interface {
+	Leer(velocidad, dt float32) Input
+}
+
+// Entrada guionada: devuelve un paso por tick y, al terminarse el guion, no aprieta nada
+type entradaGuion struct {
+	pasos  []Input
+	indice int
+}
+
+func (guion *entradaGuion) Leer(_, _ float32) Input {
+	if guion.indice >= len(guion.pasos) {
+		return Input{}
+	}
+	paso := guion.pasos[guion.indice]
+	guion.indice++
+	return paso
+}
+
+// Varias fuentes a la vez (teclado, mouse y mando): una tecla esta apretada si lo esta en alguna
+// y los movimientos analogicos se suman
+type entradaCombinada struct {
+	fuentes []InputSource
+}
+
+func (combinada entradaCombinada) Leer(velocidad, dt float32) Input {
+	var input Input
+	for _, fuente := range combinada.fuentes {
+		leido := fuente.Leer(velocidad, dt)
+		input.izquierda = input.izquierda || leido.izquierda
+		input.derecha = input.derecha || leido.derecha
+		input.lanzar = input.lanzar || leido.lanzar
+		input.disparar = input.disparar || leido.disparar
+		input.analogico += leido.analogico
+	}
+	return input
+}
+
+// Redondeamos el movimiento analogico a la resolucion con la que se graba
+func cuantizarAnalogico(analogico float32) float32 {
+	return float32(math.Round(float64(analogico)*pasosAnalogico)) / pasosAnalogico
+}
+
+// Entrada automatica: sigue con la barra a la primera pelota y lanza apenas puede
+type entradaBot struct {
+	juego *Game
+}
+
+func (bot entradaBot) Leer(_, _ float32) Input {
+	jugador := bot.juego.jugador
+	objetivo := jugador.pelotas[0].pos.x
+
+	return Input{
+		izquierda: objetivo < jugador.pos.x-float32(jugador.ancho)/4,
+		derecha:   objetivo > jugador.pos.x+float32(jugador.ancho)/4,
+		lanzar:    true,
+		disparar:  jugador.laser,
+	}
+}
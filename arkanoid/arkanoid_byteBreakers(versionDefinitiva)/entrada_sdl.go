@@ -0,0 +1,135 @@
+//go:build !headless
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Entrada desde el teclado de SDL (slice de sdl.GetKeyboardState()) con las teclas de la configuracion.
+// Las teclas se leen por puntero para que un cambio en el menu de opciones valga desde el tick siguiente
+type entradaTeclado struct {
+	teclado []uint8
+	teclas  *[cantidadAcciones]sdl.Scancode
+}
+
+func (entrada entradaTeclado) Leer(_, _ float32) Input {
+	return Input{
+		izquierda: entrada.teclado[entrada.teclas[accionIzquierda]] != 0,
+		derecha:   entrada.teclado[entrada.teclas[accionDerecha]] != 0,
+		lanzar:    entrada.teclado[entrada.teclas[accionLanzar]] != 0,
+		disparar:  entrada.teclado[entrada.teclas[accionDisparar]] != 0,
+	}
+}
+
+// Codigos de SDL de las teclas de la configuracion. Si un nombre no es una tecla de SDL esa accion
+// queda con su tecla por defecto
+func teclasSDL(conf configuracion) ([cantidadAcciones]sdl.Scancode, error) {
+	var teclas [cantidadAcciones]sdl.Scancode
+	var errores []error
+	for accion, nombre := range conf.teclas {
+		teclas[accion] = sdl.GetScancodeFromName(nombre)
+		if teclas[accion] == sdl.SCANCODE_UNKNOWN {
+			errores = append(errores, fmt.Errorf("tecla %q de %s desconocida", nombre, nombresAcciones[accion]))
+			teclas[accion] = sdl.GetScancodeFromName(teclasPorDefecto[accion])
+		}
+	}
+	return teclas, errors.Join(errores...)
+}
+
+// Entrada desde el mouse en modo relativo: junta el movimiento horizontal de los eventos entre dos ticks
+// y lo entrega escalado por la sensibilidad. El boton izquierdo lanza y el derecho dispara
+type entradaMouse struct {
+	sensibilidad *int // Porcentaje de la configuracion
+	movimiento   float32
+	izquierdo    bool
+	derecho      bool
+}
+
+// Metodo que anota un evento del mouse
+func (mouse *entradaMouse) evento(evento sdl.Event) {
+	switch e := evento.(type) {
+	case *sdl.MouseMotionEvent:
+		mouse.movimiento += float32(e.XRel) * float32(*mouse.sensibilidad) / 100
+	case *sdl.MouseButtonEvent:
+		switch e.Button {
+		case sdl.BUTTON_LEFT:
+			mouse.izquierdo = e.State == sdl.PRESSED
+		case sdl.BUTTON_RIGHT:
+			mouse.derecho = e.State == sdl.PRESSED
+		}
+	}
+}
+
+func (mouse *entradaMouse) Leer(_, _ float32) Input {
+	input := Input{
+		lanzar:    mouse.izquierdo,
+		disparar:  mouse.derecho,
+		analogico: mouse.movimiento,
+	}
+	mouse.movimiento = 0
+	return input
+}
+
+// Zona muerta de la palanca del mando (fraccion del recorrido que no mueve la barra)
+const zonaMuertaMando = 0.2
+
+// Entrada desde los mandos de SDL conectados. La palanca izquierda mueve la barra hasta la velocidad del
+// jugador segun cuanto se incline, la cruceta la mueve como las flechas del teclado, A lanza y X dispara
+type entradaMando struct {
+	mandos map[sdl.JoystickID]*sdl.GameController
+}
+
+// Metodo que abre o cierra los mandos que se conectan o desconectan con el juego andando
+// (SDL tambien avisa al arrancar de los que ya estaban conectados)
+func (mando *entradaMando) evento(e *sdl.ControllerDeviceEvent) {
+	switch e.Type {
+	case sdl.CONTROLLERDEVICEADDED:
+		// Al conectarse 'Which' es el indice del dispositivo
+		if !sdl.IsGameController(int(e.Which)) {
+			return
+		}
+		control := sdl.GameControllerOpen(int(e.Which))
+		if control == nil {
+			fmt.Println("Error apertura mando:", sdl.GetError())
+			return
+		}
+		mando.mandos[control.Joystick().InstanceID()] = control
+
+	case sdl.CONTROLLERDEVICEREMOVED:
+		// Al desconectarse 'Which' es el identificador del mando abierto
+		if control, ok := mando.mandos[e.Which]; ok {
+			control.Close()
+			delete(mando.mandos, e.Which)
+		}
+	}
+}
+
+// Metodo que cierra todos los mandos abiertos
+func (mando *entradaMando) cerrar() {
+	for id, control := range mando.mandos {
+		control.Close()
+		delete(mando.mandos, id)
+	}
+}
+
+func (mando *entradaMando) Leer(velocidad, dt float32) Input {
+	var input Input
+	for _, control := range mando.mandos {
+		input.izquierda = input.izquierda || control.Button(sdl.CONTROLLER_BUTTON_DPAD_LEFT) != 0
+		input.derecha = input.derecha || control.Button(sdl.CONTROLLER_BUTTON_DPAD_RIGHT) != 0
+		input.lanzar = input.lanzar || control.Button(sdl.CONTROLLER_BUTTON_A) != 0
+		input.disparar = input.disparar || control.Button(sdl.CONTROLLER_BUTTON_X) != 0
+
+		// Pasada la zona muerta la inclinacion va de 0 a 1 y la barra se mueve esa fraccion de su velocidad
+		inclinacion := float32(control.Axis(sdl.CONTROLLER_AXIS_LEFTX)) / 32767
+		if abs32(inclinacion) > zonaMuertaMando {
+			fraccion := (abs32(inclinacion) - zonaMuertaMando) / (1 - zonaMuertaMando)
+			input.analogico += signo32(inclinacion) * min32(fraccion, 1) * velocidad * dt * frecuenciaBase
+		}
+	}
+	return input
+}
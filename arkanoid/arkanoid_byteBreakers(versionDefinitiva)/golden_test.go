@@ -0,0 +1,245 @@
+package main
+
+import (
+	"flag"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ----------------------------------------------------------------------------
+// ----------------------------IMAGENES DE REFERENCIA--------------------------
+// ----------------------------------------------------------------------------
+
+// go test -run TestGolden -update reescribe las referencias con el dibujo actual
+var (
+	actualizarGolden = flag.Bool("update", false, "reescribir las imagenes de referencia de golden/ con el dibujo actual")
+	toleranciaGolden = flag.Int("tolerancia", 0, "diferencia maxima por canal de cada pixel contra la referencia")
+)
+
+// Carpeta de las imagenes de referencia y carpeta donde escribimos el dibujo y las diferencias de las escenas que fallan
+const (
+	carpetaGolden = "golden"
+	carpetaDiff   = "golden-diff"
+)
+
+// Escena de prueba: dibuja algo fijo (sin azar ni tiempo) en una ventana vacia
+type escenaGolden struct {
+	nombre  string
+	dibujar func(t *testing.T, ventana []byte)
+}
+
+// Escenas con cada cosa que se dibuja en la ventana. Su imagen de referencia es golden/<nombre>.png
+var escenasGolden = []escenaGolden{
+	{"ladrillos", func(t *testing.T, ventana []byte) {
+		paleta := []color{{255, 0, 0, 255}, {0, 255, 0, 255}, {0, 0, 255, 255}, {255, 255, 0, 255}, {128, 128, 128, 255}}
+		juego := &Game{}
+		for i, c := range paleta {
+			juego.muro = append(juego.muro, ladrillo{pos: pos{float32(60 + i*60), 100}, ancho: 50, alto: 20, color: c, resist: i + 1})
+		}
+		for tipo := ladrilloOro; tipo < cantidadTipos; tipo++ {
+			datos := tiposLadrillo[tipo]
+			juego.muro = append(juego.muro, ladrillo{pos: pos{float32(60 + int(tipo)*60), 140}, ancho: 50, alto: 20, color: datos.color, resist: 1, tipo: tipo})
+		}
+		dibujarEscena(juego, &barra{}, ventana, "ladrillo")
+	}},
+	{"barra", func(t *testing.T, ventana []byte) {
+		jugador := barra{pos: pos{300, 750}, ancho: 100, alto: 10, color: color{255, 255, 255, 255}, vida: 3, score: 120}
+		dibujarEscena(&Game{}, &jugador, ventana, "barra", "vidas", "puntaje")
+	}},
+	{"pelota", func(t *testing.T, ventana []byte) {
+		var jugador barra
+		for i, radio := range []float32{3, 5, 8, 12} {
+			jugador.pelotas = append(jugador.pelotas, pelota{pos: pos{100 + float32(i)*100 + 0.5, 400.25}, radio: radio, color: color{255, 255, 255, 255}})
+		}
+		dibujarEscena(&Game{}, &jugador, ventana, "pelota")
+	}},
+	{"puntaje", func(t *testing.T, ventana []byte) {
+		// Dos puntajes con distinto estilo, dibujados igual que el del marcador
+		defer func(estilo estiloPuntaje) { puntajeMarcador = estilo }(puntajeMarcador)
+		puntajeMarcador = estiloPuntaje{3, color{255, 255, 255, 255}, func(int) pos { return pos{100, 300} }}
+		dibujos := dibujosEscena(&Game{}, &barra{score: 1234567890}, "puntaje")
+		puntajeMarcador = estiloPuntaje{5, color{255, 200, 0, 255}, func(int) pos { return pos{200, 400} }}
+		dibujos = append(dibujos, dibujosEscena(&Game{}, &barra{score: 9876}, "puntaje")...)
+		dibujarRegion(ventanaEntera, dibujos, color{}, ventana)
+	}},
+	{"vida", func(t *testing.T, ventana []byte) {
+		dibujarEscena(&Game{}, &barra{vida: maxVidas}, ventana, "vidas")
+	}},
+	{"poderes", func(t *testing.T, ventana []byte) {
+		juego := &Game{}
+		for p := poder(0); p < cantidadPoderes; p++ {
+			juego.capsulas = append(juego.capsulas, capsula{pos: pos{float32(60 + int(p)*70), 300}, ancho: anchoCapsula, alto: altoCapsula, poder: p})
+			juego.efectos.restante[p] = 1
+		}
+		dibujarEscena(juego, &barra{}, ventana, "capsula", "poder")
+	}},
+	{"enemigos", func(t *testing.T, ventana []byte) {
+		juego := &Game{puertaAbierta: 1, tiempoPuerta: 1}
+		for patron := patronEnemigo(0); patron < cantidadPatrones; patron++ {
+			juego.enemigos = append(juego.enemigos, enemigo{pos: pos{float32(150 + int(patron)*150), 200}, radio: radioEnemigo, color: color{255, 200, 0, 255}, patron: patron})
+		}
+		juego.disparos = []disparo{{pos: pos{300, 500}, ancho: anchoDisparo, alto: altoDisparo, color: color{255, 60, 60, 255}}}
+		dibujarEscena(juego, &barra{}, ventana, "puerta", "enemigo", "disparo")
+	}},
+	{"jefe", func(t *testing.T, ventana []byte) {
+		juego := &Game{jefe: nuevoJefe(&datosJefe{vida: 10, ancho: 300, alto: 160, pos: pos{300, 160}})}
+		juego.jefe.vida = 6
+		juego.proyectiles = []proyectil{{pos: pos{280, 400}, radio: radioProyectil, color: color{255, 80, 40, 255}}}
+		dibujarEscena(juego, &barra{}, ventana, "jefe", "proyectil", "vida jefe")
+	}},
+	{"transparencia", func(t *testing.T, ventana []byte) {
+		// Ladrillos semitransparentes sobre franjas opacas: cada uno tiene que mezclarse con lo que tiene abajo
+		juego := &Game{}
+		for i, c := range []color{{255, 255, 255, 255}, {255, 0, 0, 255}, {0, 0, 255, 255}} {
+			juego.muro = append(juego.muro, ladrillo{pos: pos{anchoVentana / 2, float32(250 + i*100)}, ancho: anchoVentana, alto: 100, color: c, resist: 1})
+		}
+		for i, alfa := range []byte{0, 60, 120, 190, 255} {
+			juego.muro = append(juego.muro, ladrillo{pos: pos{float32(60 + i*120), 350}, ancho: 80, alto: 250, color: color{0, 255, 0, alfa}, resist: 1})
+		}
+		dibujarEscena(juego, &barra{}, ventana, "ladrillo")
+	}},
+	{"partida", func(t *testing.T, ventana []byte) {
+		dibujarPartida(juegoGolden(t), 1, ventana)
+	}},
+	{"pausa", func(t *testing.T, ventana []byte) {
+		juego := juegoGolden(t)
+		juego.pausar()
+		dibujarPartida(juego, 1, ventana)
+	}},
+	{"derrota", func(t *testing.T, ventana []byte) {
+		juego := juegoGolden(t)
+		juego.estado = loose
+		dibujarPartida(juego, 1, ventana)
+	}},
+}
+
+// Dibujamos como en la partida, capa por capa, solo los elementos de la escena (sin las puertas, el puntaje
+// ni nada de lo que la partida dibuja siempre)
+func dibujarEscena(juego *Game, jugador *barra, ventana []byte, elementos ...string) {
+	dibujarRegion(ventanaEntera, dibujosEscena(juego, jugador, elementos...), color{}, ventana)
+}
+
+// Los dibujos de esos elementos, en el orden de las capas
+func dibujosEscena(juego *Game, jugador *barra, elementos ...string) []dibujo {
+	var dibujos []dibujo
+	for c := capaLadrillos; c <= capaMarcador; c++ {
+		for _, d := range agregarCapa(nil, c, juego, jugador) {
+			for _, elemento := range elementos {
+				if d.aspecto.elemento == elemento {
+					dibujos = append(dibujos, d)
+				}
+			}
+		}
+	}
+	return dibujos
+}
+
+// Partida recien empezada con los niveles que vienen con el juego
+func juegoGolden(t *testing.T) *Game {
+	t.Helper()
+	niveles, err := elegirNiveles("", "")
+	if err != nil {
+		t.Fatal("Error carga niveles:", err)
+	}
+	return nuevoJuego(nil, niveles, 1)
+}
+
+// Verificamos cada escena contra su imagen de referencia. Cada canal de cada pixel puede diferir hasta la tolerancia;
+// si una escena falla escribimos en carpetaDiff lo que se dibujo y una imagen con los pixeles distintos en rojo
+func TestGolden(t *testing.T) {
+	for _, escena := range escenasGolden {
+		t.Run(escena.nombre, func(t *testing.T) {
+			ventana := nuevaVentana()
+			escena.dibujar(t, ventana)
+			actual := imagenVentana(ventana)
+			ruta := filepath.Join(carpetaGolden, escena.nombre+".png")
+
+			if *actualizarGolden {
+				if err := os.MkdirAll(carpetaGolden, 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := guardarImagen(ruta, actual); err != nil {
+					t.Fatal(err)
+				}
+				t.Log("referencia actualizada:", ruta)
+				return
+			}
+
+			referencia, err := leerImagen(ruta)
+			if err != nil {
+				t.Fatal("Error referencia:", err)
+			}
+
+			distintos, diff := compararImagenes(referencia, actual, *toleranciaGolden)
+			if distintos == 0 {
+				return
+			}
+
+			if err := os.MkdirAll(carpetaDiff, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := guardarImagen(filepath.Join(carpetaDiff, escena.nombre+".actual.png"), actual); err != nil {
+				t.Fatal(err)
+			}
+			if err := guardarImagen(filepath.Join(carpetaDiff, escena.nombre+".diff.png"), diff); err != nil {
+				t.Fatal(err)
+			}
+			t.Errorf("%d pixeles distintos a %s (dibujo y diferencias en %s)", distintos, ruta, carpetaDiff)
+		})
+	}
+}
+
+// Cantidad de pixeles con algun canal que difiere en mas de 'tolerancia' y la imagen de diferencias:
+// la referencia atenuada de fondo y los pixeles distintos en rojo
+func compararImagenes(referencia, actual *image.RGBA, tolerancia int) (int, *image.RGBA) {
+	diff := image.NewRGBA(actual.Bounds())
+	if referencia.Bounds() != actual.Bounds() {
+		for i := 0; i+3 < len(diff.Pix); i += 4 {
+			diff.Pix[i], diff.Pix[i+3] = 255, 255
+		}
+		return actual.Bounds().Dx() * actual.Bounds().Dy(), diff
+	}
+
+	distintos := 0
+	for i := 0; i+3 < len(actual.Pix); i += 4 {
+		igual := true
+		for canal := 0; canal < 4; canal++ {
+			diferencia := int(actual.Pix[i+canal]) - int(referencia.Pix[i+canal])
+			if diferencia > tolerancia || -diferencia > tolerancia {
+				igual = false
+			}
+		}
+
+		if igual {
+			for canal := 0; canal < 3; canal++ {
+				diff.Pix[i+canal] = referencia.Pix[i+canal] / 4
+			}
+		} else {
+			distintos++
+			diff.Pix[i] = 255
+		}
+		diff.Pix[i+3] = 255
+	}
+	return distintos, diff
+}
+
+// Leemos un PNG como imagen RGBA
+func leerImagen(ruta string) (*image.RGBA, error) {
+	archivo, err := os.Open(ruta)
+	if err != nil {
+		return nil, err
+	}
+	defer archivo.Close()
+
+	imagen, err := png.Decode(archivo)
+	if err != nil {
+		return nil, err
+	}
+	rgba := image.NewRGBA(imagen.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), imagen, imagen.Bounds().Min, draw.Src)
+	return rgba, nil
+}
@@ -0,0 +1,143 @@
+package main
+
+// ------------------------------------------------------------------------------------
+// -----------------------------------GRILLA-------------------------------------------
+// ------------------------------------------------------------------------------------
+
+// Grilla uniforme sobre el muro para consultar solo los ladrillos cercanos al recorrido de la pelota
+type grilla struct {
+	origen     pos     // Esquina superior izquierda de la grilla
+	anchoCelda float32 // Tamaño de cada celda (el del ladrillo mas grande mas la separacion)
+	altoCelda  float32
+	columnas   int
+	filas      int
+	celdas     [][]int // Indices de los ladrillos que tocan cada celda (fila por fila)
+
+	marca      []int // Ultima consulta en la que vimos cada ladrillo, para no devolverlo repetido
+	consulta   int
+	candidatos []int // Se reutiliza en cada consulta
+}
+
+// Armamos la grilla con la disposicion de los ladrillos del muro
+func nuevaGrilla(muro []ladrillo) *grilla {
+	grilla := &grilla{marca: make([]int, len(muro))}
+	if len(muro) == 0 {
+		return grilla
+	}
+
+	minimo, maximo := bordesLadrillo(muro[0])
+	for _, ladrillo := range muro {
+		desde, hasta := bordesLadrillo(ladrillo)
+		// Los moviles pueden recorrer todo el ancho de la ventana
+		if ladrillo.tipo == ladrilloMovil {
+			desde.x, hasta.x = 0, float32(anchoVentana)
+		}
+		minimo = pos{min32(minimo.x, desde.x), min32(minimo.y, desde.y)}
+		maximo = pos{max32(maximo.x, hasta.x), max32(maximo.y, hasta.y)}
+		grilla.anchoCelda = max32(grilla.anchoCelda, float32(ladrillo.ancho+1))
+		grilla.altoCelda = max32(grilla.altoCelda, float32(ladrillo.alto+1))
+	}
+
+	grilla.origen = minimo
+	grilla.columnas = int((maximo.x-minimo.x)/grilla.anchoCelda) + 1
+	grilla.filas = int((maximo.y-minimo.y)/grilla.altoCelda) + 1
+	grilla.celdas = make([][]int, grilla.columnas*grilla.filas)
+
+	for i, ladrillo := range muro {
+		grilla.insertar(i, ladrillo)
+	}
+
+	return grilla
+}
+
+// Metodo que agrega el ladrillo en las celdas que toca
+func (grilla *grilla) insertar(indice int, ladrillo ladrillo) {
+	desde, hasta := bordesLadrillo(ladrillo)
+	c0, f0 := grilla.celda(desde)
+	c1, f1 := grilla.celda(hasta)
+	for f := f0; f <= f1; f++ {
+		for c := c0; c <= c1; c++ {
+			grilla.celdas[f*grilla.columnas+c] = append(grilla.celdas[f*grilla.columnas+c], indice)
+		}
+	}
+}
+
+// Metodo que quita el ladrillo de las celdas que tocaba
+func (grilla *grilla) quitar(indice int, ladrillo ladrillo) {
+	desde, hasta := bordesLadrillo(ladrillo)
+	c0, f0 := grilla.celda(desde)
+	c1, f1 := grilla.celda(hasta)
+	for f := f0; f <= f1; f++ {
+		for c := c0; c <= c1; c++ {
+			celda := grilla.celdas[f*grilla.columnas+c]
+			for i, otro := range celda {
+				if otro == indice {
+					grilla.celdas[f*grilla.columnas+c] = append(celda[:i], celda[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+}
+
+// Metodo que actualiza las celdas de un ladrillo que se movio de 'anterior' a 'actual'
+func (grilla *grilla) mover(indice int, anterior, actual ladrillo) {
+	grilla.quitar(indice, anterior)
+	grilla.insertar(indice, actual)
+}
+
+// Esquinas superior izquierda e inferior derecha del ladrillo
+func bordesLadrillo(ladrillo ladrillo) (pos, pos) {
+	mitadAncho := float32(ladrillo.ancho) / 2
+	mitadAlto := float32(ladrillo.alto) / 2
+	return pos{ladrillo.pos.x - mitadAncho, ladrillo.pos.y - mitadAlto}, pos{ladrillo.pos.x + mitadAncho, ladrillo.pos.y + mitadAlto}
+}
+
+// Metodo que devuelve la celda (columna, fila) de la coordenada, limitada a los bordes de la grilla
+func (grilla *grilla) celda(coordenada pos) (int, int) {
+	columna := int((coordenada.x - grilla.origen.x) / grilla.anchoCelda)
+	fila := int((coordenada.y - grilla.origen.y) / grilla.altoCelda)
+	return limitar(columna, 0, grilla.columnas-1), limitar(fila, 0, grilla.filas-1)
+}
+
+func limitar(valor, minimo, maximo int) int {
+	if valor < minimo {
+		return minimo
+	}
+	if valor > maximo {
+		return maximo
+	}
+	return valor
+}
+
+// Metodo que devuelve los ladrillos de las celdas que toca el rectangulo [minimo, maximo], sin repetir.
+// El slice se reutiliza en la siguiente consulta
+func (grilla *grilla) consultar(minimo, maximo pos) []int {
+	grilla.consulta++
+	grilla.candidatos = grilla.candidatos[:0]
+
+	if grilla.columnas == 0 {
+		return grilla.candidatos
+	}
+
+	// Si el rectangulo queda fuera de la grilla no hay nada que revisar
+	finGrilla := pos{grilla.origen.x + float32(grilla.columnas)*grilla.anchoCelda, grilla.origen.y + float32(grilla.filas)*grilla.altoCelda}
+	if maximo.x < grilla.origen.x || maximo.y < grilla.origen.y || minimo.x > finGrilla.x || minimo.y > finGrilla.y {
+		return grilla.candidatos
+	}
+
+	c0, f0 := grilla.celda(minimo)
+	c1, f1 := grilla.celda(maximo)
+	for f := f0; f <= f1; f++ {
+		for c := c0; c <= c1; c++ {
+			for _, i := range grilla.celdas[f*grilla.columnas+c] {
+				if grilla.marca[i] != grilla.consulta {
+					grilla.marca[i] = grilla.consulta
+					grilla.candidatos = append(grilla.candidatos, i)
+				}
+			}
+		}
+	}
+
+	return grilla.candidatos
+}
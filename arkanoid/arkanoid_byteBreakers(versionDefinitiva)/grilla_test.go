@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// Colision de pelotas contra muros de distintas medidas, probando todos los ladrillos (fuerza bruta) y solo
+// los de la grilla. Cada operacion es un tick de todas las pelotas
+func BenchmarkBarrido(b *testing.B) {
+	muros := []struct{ columnas, filas int }{{9, 17}, {40, 60}, {120, 160}}
+	cantidades := []int{1, 12, 48}
+
+	for _, medida := range muros {
+		muro := muroPrueba(medida.columnas, medida.filas)
+		grilla := nuevaGrilla(muro)
+
+		for _, cantidad := range cantidades {
+			pelotas := pelotasPrueba(muro, cantidad)
+			nombre := fmt.Sprintf("muro=%dx%d/pelotas=%d", medida.columnas, medida.filas, cantidad)
+
+			b.Run(nombre+"/fuerza-bruta", func(b *testing.B) { benchmarkBarrido(b, muro, nil, pelotas) })
+			b.Run(nombre+"/grilla", func(b *testing.B) { benchmarkBarrido(b, muro, grilla, pelotas) })
+		}
+	}
+}
+
+// Un tick de todas las pelotas contra el muro (las pelotas se copian para que cada iteracion sea igual)
+func benchmarkBarrido(b *testing.B, muro []ladrillo, grilla *grilla, pelotas []pelota) {
+	for n := 0; n < b.N; n++ {
+		for _, bola := range pelotas {
+			bola.barrido(1/float32(ticksPorSegundo), obstaculos{muro: muro, grilla: grilla}, nil)
+		}
+	}
+}
+
+// Muro de columnas x filas ladrillos de 10x6 pegados desde la esquina superior izquierda
+func muroPrueba(columnas, filas int) []ladrillo {
+	muro := make([]ladrillo, 0, columnas*filas)
+	for f := 0; f < filas; f++ {
+		for c := 0; c < columnas; c++ {
+			muro = append(muro, ladrillo{pos: pos{float32(c*11) + 5, float32(f*7) + 3}, ancho: 10, alto: 6, resist: 5, extScore: 10})
+		}
+	}
+	return muro
+}
+
+// Pelotas en posiciones y direcciones al azar (con semilla fija) dentro del area del muro
+func pelotasPrueba(muro []ladrillo, cantidad int) []pelota {
+	azar := rand.New(rand.NewSource(1))
+	ultimo := muro[len(muro)-1].pos
+
+	pelotas := make([]pelota, cantidad)
+	for i := range pelotas {
+		pelotas[i] = pelota{
+			pos:   pos{azar.Float32() * ultimo.x, azar.Float32() * ultimo.y},
+			radio: 5,
+			vel_x: float32(velocidades_x[azar.Intn(len(velocidades_x))]),
+			vel_y: 10,
+		}
+	}
+	return pelotas
+}
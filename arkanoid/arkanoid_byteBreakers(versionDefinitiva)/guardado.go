@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ------------------------------------------------------------------------------------
+// ----------------------------------GUARDADO------------------------------------------
+// ------------------------------------------------------------------------------------
+
+// Carpeta del juego dentro de la configuracion del usuario (~/.config/arkanoid-bytebreakers en Linux)
+func directorioJuego() (string, error) {
+	configuracion, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	directorio := filepath.Join(configuracion, "arkanoid-bytebreakers")
+	if err := os.MkdirAll(directorio, 0o755); err != nil {
+		return "", err
+	}
+	return directorio, nil
+}
+
+// Escribimos el archivo completo o nada: primero a un temporal en la misma carpeta y despues lo renombramos
+func escribirArchivoAtomico(ruta string, datos []byte) error {
+	temporal, err := os.CreateTemp(filepath.Dir(ruta), filepath.Base(ruta)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temporal.Name())
+
+	if _, err := temporal.Write(datos); err != nil {
+		temporal.Close()
+		return err
+	}
+	if err := temporal.Sync(); err != nil {
+		temporal.Close()
+		return err
+	}
+	if err := temporal.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temporal.Name(), ruta)
+}
+
+// Progreso de las campañas: por cada campaña (identificada por su archivo) el nivel mas alto alcanzado.
+// Una linea por campaña: "<nivel> <campaña>"
+type progreso map[string]int
+
+func rutaProgreso() (string, error) {
+	directorio, err := directorioJuego()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(directorio, "progreso.txt"), nil
+}
+
+// Leemos el progreso guardado. Si el archivo no existe o tiene lineas rotas las ignoramos
+func leerProgreso() progreso {
+	progreso := make(progreso)
+
+	ruta, err := rutaProgreso()
+	if err != nil {
+		return progreso
+	}
+	datos, err := os.ReadFile(ruta)
+	if err != nil {
+		return progreso
+	}
+
+	for _, linea := range strings.Split(string(datos), "\n") {
+		nivel, nombre, ok := strings.Cut(strings.TrimSpace(linea), " ")
+		if !ok {
+			continue
+		}
+		numero, err := strconv.Atoi(nivel)
+		if err != nil || numero < 0 {
+			continue
+		}
+		progreso[nombre] = numero
+	}
+
+	return progreso
+}
+
+// Guardamos el nivel alcanzado (desde 1) en la campaña si supera al que ya estaba guardado
+func guardarProgreso(nombre string, nivel int) error {
+	progreso := leerProgreso()
+	if anterior, ok := progreso[nombre]; ok && anterior >= nivel {
+		return nil
+	}
+	progreso[nombre] = nivel
+
+	ruta, err := rutaProgreso()
+	if err != nil {
+		return err
+	}
+
+	nombres := make([]string, 0, len(progreso))
+	for nombre := range progreso {
+		nombres = append(nombres, nombre)
+	}
+	sort.Strings(nombres)
+
+	var datos strings.Builder
+	for _, nombre := range nombres {
+		fmt.Fprintf(&datos, "%d %s\n", progreso[nombre], nombre)
+	}
+	return escribirArchivoAtomico(ruta, []byte(datos.String()))
+}
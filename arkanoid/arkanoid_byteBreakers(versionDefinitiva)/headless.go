@@ -0,0 +1,133 @@
+//go:build headless
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// ----------------------------------------------------------------------------
+// ------------------------------MAIN SIN VENTANA-------------------------------
+// ----------------------------------------------------------------------------
+
+// Corre partidas simuladas sin SDL (go build -tags headless) para probar la logica en maquinas sin pantalla
+func main() {
+	partidas := flag.Int("partidas", 100, "cantidad de partidas a simular")
+	maxTicks := flag.Int("ticks", 20000, "ticks maximos por partida")
+	frecuencia := flag.Int("tickrate", ticksPorSegundo, "ticks por segundo de la simulacion")
+	rutaNivel := flag.String("nivel", "", "archivo de un unico nivel a simular")
+	rutaLista := flag.String("niveles", "", "archivo con la lista de niveles de la campaña (por defecto la incluida)")
+	semilla := flag.Int64("semilla", 1, "semilla del azar de la primera partida (las siguientes usan semilla+1, semilla+2...)")
+	rutaGrabar := flag.String("grabar", "", "archivo donde grabar la primera partida simulada")
+	rutaReplay := flag.String("replay", "", "archivo de repeticion a verificar en vez de simular partidas (sus niveles se buscan entre los incluidos y los de -nivel o -niveles)")
+	carpetaPNG := flag.String("png", "", "carpeta donde exportar fotogramas de la primera partida simulada como PNG")
+	cadaPNG := flag.Int("png-cada", 60, "exportar un fotograma cada tantos ticks (1 los exporta todos)")
+	flag.Parse()
+
+	niveles, err := elegirNiveles(*rutaLista, *rutaNivel)
+	if err != nil {
+		fmt.Println("Error carga nivel:", err)
+		os.Exit(1)
+	}
+
+	if *rutaReplay != "" {
+		if err := verificarRepeticion(*rutaReplay, niveles); err != nil {
+			fmt.Println("Error repeticion:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	ganadas := 0
+	for i := 0; i < *partidas; i++ {
+		juego := nuevoJuego(nil, niveles, *semilla+int64(i))
+		juego.jugador.control = entradaBot{juego}
+		juego.fijarFrecuencia(*frecuencia)
+
+		var grabando *grabacion
+		if *rutaGrabar != "" && i == 0 {
+			grabando = &grabacion{
+				semilla:    juego.semilla,
+				frecuencia: *frecuencia,
+				niveles:    huellasNiveles(niveles),
+				velocidad:  int(juego.jugador.vel_x),
+				vidas:      juego.jugador.vida,
+			}
+			juego.jugador.control = &entradaGrabada{juego.jugador.control, grabando}
+		}
+
+		var exportador *exportadorPNG
+		var ventana []byte
+		if *carpetaPNG != "" && i == 0 {
+			if err := os.MkdirAll(*carpetaPNG, 0o755); err != nil {
+				fmt.Println("Error carpeta PNG:", err)
+				os.Exit(1)
+			}
+			exportador = &exportadorPNG{carpeta: *carpetaPNG, cada: *cadaPNG}
+			ventana = nuevaVentana()
+		}
+
+		tick := 0
+		superados := 0
+		for ; tick < *maxTicks && juego.estado != win && juego.estado != loose; tick++ {
+			juego.Step()
+
+			if exportador != nil {
+				if err := exportador.exportar(juego, ventana); err != nil {
+					fmt.Println("Error exportacion PNG:", err)
+					os.Exit(1)
+				}
+			}
+
+			// El contador de ladrillos del juego tiene que coincidir siempre con el muro
+			if juego.restantes != contarRestantes(juego.muro) {
+				fmt.Printf("Error contador ladrillos: partida %d tick %d: el contador dice %d y en el muro hay %d\n", i+1, juego.tick, juego.restantes, contarRestantes(juego.muro))
+				os.Exit(1)
+			}
+			for _, evento := range juego.tomarEventos() {
+				if evento.tipo == eventoNivelSuperado {
+					superados++
+				}
+			}
+		}
+
+		if juego.estado == win {
+			ganadas++
+		}
+		if grabando != nil {
+			grabando.cerrar(juego)
+			if err := guardarGrabacion(*rutaGrabar, grabando); err != nil {
+				fmt.Println("Error guardado repeticion:", err)
+				os.Exit(1)
+			}
+		}
+		fmt.Printf("partida %d: ticks %d nivel %d superados %d score %d vida %d ladrillos %d\n", i+1, tick, juego.nivelActual+1, superados, juego.jugador.score, juego.jugador.vida, juego.restantes)
+	}
+
+	fmt.Printf("ganadas %d de %d\n", ganadas, *partidas)
+}
+
+// Reproducimos la repeticion hasta su ultimo tick y verificamos que termine con el mismo score, vida y muro.
+// Sus niveles se buscan entre los incluidos y los 'niveles' indicados
+func verificarRepeticion(ruta string, niveles []*nivel) error {
+	repeticion, err := leerGrabacion(ruta)
+	if err != nil {
+		return err
+	}
+	juego, err := juegoDesdeGrabacion(repeticion, niveles)
+	if err != nil {
+		return err
+	}
+
+	for juego.tick < len(repeticion.entradas) {
+		juego.Step()
+	}
+	if err := repeticion.coincide(juego); err != nil {
+		return err
+	}
+
+	fmt.Printf("repeticion ok: ticks %d nivel %d score %d vida %d\n", juego.tick, juego.nivelActual+1, juego.jugador.score, juego.jugador.vida)
+	return nil
+}
@@ -0,0 +1,226 @@
+package main
+
+import "math"
+
+// ------------------------------------------------------------------------------------
+// ------------------------------------JEFE--------------------------------------------
+// ------------------------------------------------------------------------------------
+
+// Reglas del jefe y sus ataques
+const (
+	intervaloAtaque     = 1.5 // Segundos entre dos proyectiles del jefe
+	velocidadProyectil  = 5   // Pixeles por fotograma
+	radioProyectil      = 4
+	velocidadJefe       = 1    // Pixeles por fotograma que se desplaza de costado
+	puntosGolpeJefe     = 50   // Puntos por cada golpe al jefe
+	puntosJefe          = 1000 // Puntos por derrotarlo
+	segundosDestello    = 0.1  // Segundos que el jefe se ve blanco despues de un golpe
+	altoBarraVidaJefe   = 8
+	margenBarraVidaJefe = 40
+	largoBarraVidaJefe  = anchoVentana - 2*margenBarraVidaJefe
+)
+
+// Datos del jefe en el archivo de nivel
+type datosJefe struct {
+	vida  int
+	ancho int
+	alto  int
+	pos   pos
+}
+
+// Jefe del nivel: un rectangulo grande que aguanta varios golpes, se desplaza de costado y dispara a la barra
+type jefe struct {
+	pos      pos
+	ancho    int
+	alto     int
+	vel_x    float32
+	color    color
+	vida     int
+	vidaMax  int
+	espera   float32 // Segundos que faltan para el proximo ataque
+	destello float32 // Segundos que le quedan al destello del ultimo golpe
+}
+
+// Proyectil del jefe: va en linea recta hacia donde estaba la barra cuando lo disparo
+type proyectil struct {
+	pos   pos
+	radio float32
+	vel_x float32
+	vel_y float32
+	color color
+}
+
+// Creamos el jefe con los datos del nivel
+func nuevoJefe(datos *datosJefe) *jefe {
+	return &jefe{
+		pos:     datos.pos,
+		ancho:   datos.ancho,
+		alto:    datos.alto,
+		vel_x:   velocidadJefe,
+		color:   color{200, 40, 160, 255}, // MAGENTA
+		vida:    datos.vida,
+		vidaMax: datos.vida,
+		espera:  intervaloAtaque,
+	}
+}
+
+// Metodo para dibujar el jefe (blanco durante el destello de un golpe)
+func (jefe *jefe) Dibujar(ventana []byte) {
+	colorJefe := jefe.colorDibujo()
+
+	startX := jefe.pos.x - float32(jefe.ancho)/2
+	startY := jefe.pos.y - float32(jefe.alto)/2
+	for y := 0; y < jefe.alto; y++ {
+		for x := 0; x < jefe.ancho; x++ {
+			colorear(pos{startX + float32(x), startY + float32(y)}, colorJefe, ventana)
+		}
+	}
+}
+
+// Metodo que indica el color con el que se ve el jefe
+func (jefe *jefe) colorDibujo() color {
+	if jefe.destello > 0 {
+		return color{255, 255, 255, 255}
+	}
+	return jefe.color
+}
+
+// Metodo que desplaza al jefe de costado rebotando en las paredes
+func (jefe *jefe) Movimiento(dt float32) {
+	jefe.pos.x += jefe.vel_x * dt * frecuenciaBase
+
+	mitad := float32(jefe.ancho) / 2
+	if jefe.pos.x-mitad < 0 || jefe.pos.x+mitad > float32(anchoVentana) {
+		jefe.vel_x = -jefe.vel_x
+		jefe.pos.x = limitarFloat(jefe.pos.x, mitad, float32(anchoVentana)-mitad)
+	}
+}
+
+// Metodo para dibujar el proyectil
+func (proyectil *proyectil) Dibujar(ventana []byte) {
+	for y := -proyectil.radio; y < proyectil.radio; y++ {
+		for x := -proyectil.radio; x < proyectil.radio; x++ {
+			if x*x+y*y < proyectil.radio*proyectil.radio {
+				colorear(pos{proyectil.pos.x + x, proyectil.pos.y + y}, proyectil.color, ventana)
+			}
+		}
+	}
+}
+
+// Metodo que mueve el proyectil
+func (proyectil *proyectil) Movimiento(dt float32) {
+	proyectil.pos.x += proyectil.vel_x * dt * frecuenciaBase
+	proyectil.pos.y += proyectil.vel_y * dt * frecuenciaBase
+}
+
+// Fase de actualizacion del jefe: se mueve, recibe los golpes del laser (los de las pelotas llegan con los impactos
+// del barrido) y dispara a la barra.
+// Si un proyectil toca la barra el jugador pierde una vida
+func (juego *Game) actualizarJefe() {
+	jefe := juego.jefe
+	if jefe == nil || jefe.vida == 0 {
+		return
+	}
+
+	llamarMovimiento(jefe, juego.dt)
+	if jefe.destello > 0 {
+		jefe.destello -= juego.dt
+	}
+
+	enJuego := juego.disparos[:0]
+	for _, disparo := range juego.disparos {
+		if jefe.vida > 0 && abs32(disparo.pos.x-jefe.pos.x) <= float32(disparo.ancho+jefe.ancho)/2 &&
+			abs32(disparo.pos.y-jefe.pos.y) <= float32(disparo.alto+jefe.alto)/2 {
+			juego.golpearJefe()
+			continue
+		}
+		enJuego = append(enJuego, disparo)
+	}
+	juego.disparos = enJuego
+
+	if jefe.vida == 0 {
+		juego.proyectiles = nil
+		return
+	}
+
+	jefe.espera -= juego.dt
+	if jefe.espera <= 0 {
+		jefe.espera = intervaloAtaque
+		juego.atacar()
+	}
+
+	jugador := &juego.jugador
+	restantes := juego.proyectiles[:0]
+	for _, proyectil := range juego.proyectiles {
+		llamarMovimiento(&proyectil, juego.dt)
+
+		if abs32(proyectil.pos.x-jugador.pos.x) <= float32(jugador.ancho)/2+proyectil.radio &&
+			abs32(proyectil.pos.y-jugador.pos.y) <= float32(jugador.alto)/2+proyectil.radio {
+			juego.perderVida()
+			return
+		}
+		if proyectil.pos.y-proyectil.radio < float32(altoVentana) {
+			restantes = append(restantes, proyectil)
+		}
+	}
+	juego.proyectiles = restantes
+}
+
+// El jefe dispara un proyectil desde su borde inferior hacia la barra (con un pequeño desvio al azar de la partida)
+func (juego *Game) atacar() {
+	jefe := juego.jefe
+	salida := pos{jefe.pos.x, jefe.pos.y + float32(jefe.alto)/2 + radioProyectil}
+	objetivo := pos{juego.jugador.pos.x + float32(juego.azar.Intn(41)-20), juego.jugador.pos.y}
+
+	direccion := pos{objetivo.x - salida.x, objetivo.y - salida.y}
+	largo := float32(math.Sqrt(float64(direccion.x*direccion.x + direccion.y*direccion.y)))
+	if largo == 0 {
+		return
+	}
+
+	juego.proyectiles = append(juego.proyectiles, proyectil{
+		pos:   salida,
+		radio: radioProyectil,
+		vel_x: direccion.x / largo * velocidadProyectil,
+		vel_y: direccion.y / largo * velocidadProyectil,
+		color: color{255, 80, 40, 255}, // ROJO ANARANJADO
+	})
+}
+
+// Le sacamos una vida al jefe y sumamos los puntos del golpe (y los de derrotarlo si era la ultima)
+func (juego *Game) golpearJefe() {
+	jefe := juego.jefe
+	if jefe.vida == 0 {
+		return
+	}
+
+	jefe.vida--
+	jefe.destello = segundosDestello
+	juego.sumarPuntaje(puntosGolpeJefe)
+	if jefe.vida == 0 {
+		juego.sumarPuntaje(puntosJefe)
+	}
+}
+
+// Metodo que indica el largo en pixeles de la parte llena de la barra de vida
+func (jefe *jefe) largoVida() int {
+	return largoBarraVidaJefe * jefe.vida / jefe.vidaMax
+}
+
+// Grafica arriba la barra con la vida que le queda al jefe
+func graficarVidaJefe(jefe *jefe, ventana []byte) {
+	if jefe == nil {
+		return
+	}
+
+	largo := jefe.largoVida()
+	for y := 0; y < altoBarraVidaJefe; y++ {
+		for x := 0; x < largoBarraVidaJefe; x++ {
+			colorBarra := color{60, 60, 60, 255} // GRIS
+			if x < largo {
+				colorBarra = jefe.color
+			}
+			colorear(pos{float32(margenBarraVidaJefe + x), float32(12 + y)}, colorBarra, ventana)
+		}
+	}
+}
@@ -0,0 +1,423 @@
+package main
+
+import "math/rand"
+
+// ------------------------------------------------------------------------------------
+// -----------------------------------JUEGO--------------------------------------------
+// ------------------------------------------------------------------------------------
+
+// Partida completa: jugador, campaña, muro y estado. No depende de SDL, el frontend solo la avanza con Step() y la dibuja
+type Game struct {
+	jugador          barra
+	niveles          []*nivel // Niveles en el orden en que se juegan
+	nivelActual      int      // Indice en la campaña
+	nivelInicial     int      // Nivel al que se vuelve cuando el usuario pierde
+	puntosNivel      []int    // Puntos conseguidos en cada nivel de la campaña
+	muro             []ladrillo
+	grilla           *grilla // Broadphase de los ladrillos del muro
+	resistenciaColor map[int]color
+	capsulas         []capsula // Capsulas de poderes que estan cayendo
+	efectos          efectos   // Poderes activos
+	disparos         []disparo // Disparos del laser en pantalla
+	esperaLaser      float32   // Segundos que faltan para poder volver a disparar
+	enemigos         []enemigo
+	esperaEnemigo    float32 // Segundos que faltan para que salga el proximo enemigo
+	puertaAbierta    int     // Puerta por la que salio el ultimo enemigo
+	tiempoPuerta     float32 // Segundos que la puerta se sigue viendo abierta
+	jefe             *jefe   // Solo en los niveles con jefe
+	proyectiles      []proyectil
+	restantes        int      // Ladrillos que faltan romper para superar el nivel (no cuenta los de oro)
+	eventos          []evento // Eventos de la partida que el frontend todavia no leyo
+	estado           estadoJuego
+	estadoPrevio     estadoJuego // Estado al que vuelve la partida al salir de la pausa
+	dt               float32     // Segundos que avanza cada tick (paso fijo)
+	tick             int         // Ticks avanzados desde que empezo la partida
+
+	// Todo lo aleatorio de la partida sale de aca, asi con la misma semilla y las mismas entradas se repite igual
+	semilla int64
+	azar    *rand.Rand
+
+	// Copias del estado inicial para resetear la partida cuando el usuario pierde
+	copiaJugador barra
+	copiaPelota  pelota
+	copiaMuro    []ladrillo
+}
+
+// Creamos una partida nueva con el jugador (manejado por control), su pelota inicial y el muro del primer nivel de la campaña
+func nuevoJuego(control InputSource, niveles []*nivel, semilla int64) *Game {
+	juego := &Game{
+		estado:      start,
+		dt:          1 / float32(ticksPorSegundo),
+		niveles:     niveles,
+		puntosNivel: make([]int, len(niveles)),
+		semilla:     semilla,
+		azar:        rand.New(rand.NewSource(semilla)),
+	}
+
+	// Pelota inicial jugador (apoyada en la barra hasta que la lance)
+	pelota1 := pelota{
+		radio:   5,
+		vel_x:   0,
+		vel_y:   10,
+		color:   color{255, 255, 255, 255},
+		jugador: &juego.jugador,
+	}
+
+	// Jugador
+	juego.jugador = barra{
+		pos:     pos{float32(anchoVentana) / 2, float32(altoVentana) - 50},
+		ancho:   100,
+		alto:    10,
+		vel_x:   15,
+		color:   color{255, 255, 255, 255},
+		vida:    3,
+		score:   0,
+		pelotas: []pelota{pelota1},
+		control: control,
+	}
+
+	// Copiamos los atributos del jugador y pelota inicial por si el usuario pierde para resetearlo
+	juego.copiaJugador = juego.jugador
+	juego.copiaPelota = pelota1
+
+	juego.prepararNivel(0)
+
+	return juego
+}
+
+// Cambiamos la velocidad de la barra y las vidas con las que empieza el jugador (tambien al reiniciar la partida)
+func (juego *Game) configurarJugador(velocidad, vidas int) {
+	juego.copiaJugador.vel_x = float32(velocidad)
+	juego.copiaJugador.vida = vidas
+	juego.jugador.vel_x = juego.copiaJugador.vel_x
+	juego.jugador.vida = vidas
+}
+
+// Diagramamos el muro del nivel indicado de la campaña y volvemos la barra y la pelota a su lugar.
+// El puntaje y las vidas del jugador se mantienen
+func (juego *Game) prepararNivel(indice int) {
+	juego.nivelActual = indice
+	juego.cancelarPoderes()
+	juego.cancelarEnemigos()
+
+	// Diagramacion mapa y resistencias todos los ladrillos
+	juego.muro, juego.resistenciaColor = diagramar_mapa(juego.niveles[indice], indice)
+	juego.grilla = nuevaGrilla(juego.muro)
+
+	// Jefe del nivel (si tiene)
+	juego.jefe = nil
+	juego.proyectiles = nil
+	if datos := juego.niveles[indice].jefe; datos != nil {
+		juego.jefe = nuevoJefe(datos)
+	}
+
+	// Copia mapa para restaurarlo cuando el usuario pierde 3 vidas
+	juego.copiaMuro = replicaMuro(juego.muro)
+	juego.restantes = contarRestantes(juego.muro)
+
+	juego.jugador.pos = juego.copiaJugador.pos
+	juego.jugador.ancho = juego.copiaJugador.ancho
+	juego.jugador.pelotas = []pelota{juego.copiaPelota}
+	juego.apoyarPelota()
+	juego.fijarPosAnterior()
+
+	juego.estado = start
+}
+
+// Empezamos la partida (y cada reinicio despues de perder) desde el nivel indicado
+func (juego *Game) empezarEn(indice int) {
+	juego.nivelInicial = indice
+	juego.prepararNivel(indice)
+}
+
+// Cambia la cantidad de ticks por segundo de la simulacion
+func (juego *Game) fijarFrecuencia(ticks int) {
+	juego.dt = 1 / float32(ticks)
+}
+
+// Guardamos la posicion actual del jugador y las pelotas como la del tick anterior
+// (tambien despues de teletransportarlos, para que el dibujo no interpole el salto)
+func (juego *Game) fijarPosAnterior() {
+	juego.jugador.posAnterior = juego.jugador.pos
+	for i := range juego.jugador.pelotas {
+		juego.jugador.pelotas[i].posAnterior = juego.jugador.pelotas[i].pos
+	}
+}
+
+// Avanza la partida un tick de juego.dt segundos leyendo una vez la fuente de entrada del jugador
+func (juego *Game) Step() {
+	// En pausa no corre el tiempo ni se lee la entrada (asi las repeticiones no registran los ticks pausados)
+	if juego.estado == pausa {
+		return
+	}
+
+	juego.tick++
+	juego.fijarPosAnterior()
+
+	// Movimiento jugador
+	juego.jugador.leerControl(juego.dt)
+	llamarMovimiento(&juego.jugador, juego.dt)
+	input := juego.jugador.entrada
+
+	// Switch STATE juego
+	switch juego.estado {
+	// Juego en pausa
+	case start:
+		juego.apuntar()
+		if input.lanzar {
+			juego.soltarPelotas()
+			juego.estado = play
+		}
+
+	// Si el usuario esta jugando
+	case play:
+		juego.actualizarPegadas()
+
+		juego.actualizarLadrillos()
+
+		juego.actualizarPelotas()
+
+		juego.actualizarLaser()
+
+		juego.actualizarJefe()
+
+		juego.actualizarEnemigos()
+
+		juego.actualizarCapsulas()
+
+		juego.actualizarPoderes()
+
+		juego.pelotasPerdidas()
+
+		juego.verificarVictoria()
+
+		juego.verificarSalida()
+
+	// Si el usuario supero el nivel esperamos que lance para pasar al siguiente
+	case cleared:
+		if input.lanzar {
+			juego.prepararNivel(juego.nivelActual + 1)
+		}
+
+	// Si el usuario perdio restauramos el muro y esperamos que vuelva a lanzar
+	case loose:
+		for index, value := range juego.copiaMuro {
+			juego.muro[index] = value
+		}
+		juego.restantes = contarRestantes(juego.muro)
+
+		if input.lanzar {
+			juego.reiniciar()
+		}
+	}
+}
+
+// Pausamos la partida. Solo mientras se esta jugando un nivel (al perder o ganar no hay nada que pausar)
+func (juego *Game) pausar() bool {
+	if juego.estado != start && juego.estado != play && juego.estado != cleared {
+		return false
+	}
+	juego.estadoPrevio = juego.estado
+	juego.estado = pausa
+	return true
+}
+
+// Salimos de la pausa volviendo al estado en el que estaba la partida
+func (juego *Game) reanudar() {
+	if juego.estado == pausa {
+		juego.estado = juego.estadoPrevio
+	}
+}
+
+// Fase de actualizacion de las pelotas. Cada pelota solo modifica su posicion y velocidad;
+// el muro, el puntaje y la lista de pelotas son del juego y se modifican una sola vez al final del tick
+func (juego *Game) actualizarPelotas() {
+	jugador := &juego.jugador
+
+	// Con el poder lento las pelotas avanzan una fraccion del tick
+	dt := juego.dt
+	if juego.efectos.activo(poderLento) {
+		dt *= factorLento
+	}
+
+	var impactos []impacto
+	for i := range jugador.pelotas {
+		jugador.pelotas[i].barrido(dt, obstaculos{juego.muro, juego.grilla, juego.enemigos, juego.jefe}, func(c contacto) {
+			impactos = append(impactos, impacto{i, c.tipo, c.indice})
+		})
+	}
+
+	juego.aplicarImpactos(impactos)
+}
+
+// Aplicamos los golpes a los ladrillos, los enemigos y el jefe y sumamos el puntaje, en el orden en que ocurrieron
+func (juego *Game) aplicarImpactos(impactos []impacto) {
+	for _, golpe := range impactos {
+		switch golpe.tipo {
+		case contactoLadrillo:
+			juego.golpearLadrillo(golpe.indice)
+		case contactoEnemigo:
+			juego.destruirEnemigo(golpe.indice)
+		case contactoJefe:
+			juego.golpearJefe()
+		}
+	}
+}
+
+// Descontamos un punto de resistencia al ladrillo y, si se rompe, sumamos su puntaje y puede soltar una capsula.
+// Lo usan las pelotas y los disparos del laser
+func (juego *Game) golpearLadrillo(indice int) {
+	ladrillo := &juego.muro[indice]
+	if ladrillo.resist == 0 || ladrillo.tipo == ladrilloOro {
+		return
+	}
+
+	// Los normales cambian de color con cada golpe, los especiales mantienen el suyo hasta romperse
+	ladrillo.resist--
+	if ladrillo.tipo == ladrilloNormal || ladrillo.resist == 0 {
+		ladrillo.color = juego.resistenciaColor[ladrillo.resist]
+	}
+	if ladrillo.resist == 0 {
+		if ladrillo.cuentaParaGanar() {
+			juego.restantes--
+		}
+		juego.sumarPuntaje(ladrillo.extScore)
+		juego.soltarCapsula(ladrillo)
+
+		switch ladrillo.tipo {
+		case ladrilloRegenera:
+			ladrillo.regenera = segundosRegenera
+		case ladrilloExplosivo:
+			juego.explotar(indice)
+		}
+	}
+}
+
+// Sumamos los puntos al score del jugador y al del nivel actual
+func (juego *Game) sumarPuntaje(puntos int) {
+	juego.jugador.score += puntos
+	juego.puntosNivel[juego.nivelActual] += puntos
+}
+
+// Puntaje de la partida para la tabla, con el detalle de los niveles jugados
+func (juego *Game) puntajeFinal(nombre string) puntaje {
+	return puntaje{
+		nombre:   nombre,
+		score:    juego.jugador.score,
+		nivel:    juego.nivelActual + 1,
+		porNivel: append([]int(nil), juego.puntosNivel[:juego.nivelActual+1]...),
+	}
+}
+
+// Quita las pelotas que cayeron debajo de la ventana, si no queda ninguna el jugador pierde una vida
+func (juego *Game) pelotasPerdidas() {
+	jugador := &juego.jugador
+
+	enJuego := make([]pelota, 0, len(jugador.pelotas))
+	for _, bola := range jugador.pelotas {
+		if bola.pos.y < float32(altoVentana) {
+			enJuego = append(enJuego, bola)
+		}
+	}
+
+	if len(enJuego) > 0 {
+		jugador.pelotas = enJuego
+		return
+	}
+
+	juego.perderVida()
+}
+
+// El jugador pierde una vida (se cayo la ultima pelota o lo toco un proyectil del jefe): reseteamos la barra
+// y volvemos a apoyar la pelota sobre ella
+func (juego *Game) perderVida() {
+	jugador := &juego.jugador
+
+	ultima := jugador.pelotas[0]
+	ultima.vel_x = 0
+	ultima.vel_y = 10
+	ultima.pegada = false
+	jugador.pelotas = []pelota{ultima}
+	juego.cancelarPoderes()
+	juego.cancelarEnemigos()
+	juego.proyectiles = nil
+
+	jugador.pos.x = float32(anchoVentana) / 2
+	jugador.pos.y = float32(altoVentana) - 50
+	jugador.vida--
+	juego.apoyarPelota()
+
+	juego.fijarPosAnterior()
+
+	juego.estado = start
+	if jugador.vida == 0 {
+		juego.estado = loose
+	}
+}
+
+// Si no quedan ladrillos por romper el usuario supero el nivel, y si era el ultimo gano la campaña.
+// En los niveles con jefe solo hace falta derrotarlo
+func (juego *Game) verificarVictoria() {
+	if juego.jefe != nil {
+		if juego.jefe.vida == 0 {
+			juego.superarNivel()
+		}
+		return
+	}
+
+	if juego.restantes == 0 {
+		juego.superarNivel()
+	}
+}
+
+// Cantidad de ladrillos del muro que faltan romper para superar el nivel
+func contarRestantes(muro []ladrillo) int {
+	contador := 0
+	for i := range muro {
+		if muro[i].resist > 0 && muro[i].cuentaParaGanar() {
+			contador++
+		}
+	}
+	return contador
+}
+
+// El usuario supero el nivel actual: pasa al siguiente o, si era el ultimo, gano la campaña
+func (juego *Game) superarNivel() {
+	juego.eventos = append(juego.eventos, evento{eventoNivelSuperado, juego.nivelActual})
+
+	if juego.nivelActual+1 < len(juego.niveles) {
+		juego.estado = cleared
+	} else {
+		juego.estado = win
+	}
+}
+
+// Eventos de la partida para el frontend (guardar el progreso, sonidos...), la logica no depende de que alguien los lea
+type tipoEvento int
+
+const (
+	eventoNivelSuperado tipoEvento = iota
+)
+
+type evento struct {
+	tipo  tipoEvento
+	nivel int // Indice en la campaña del nivel en el que ocurrio
+}
+
+// Devolvemos los eventos ocurridos desde la ultima llamada y los olvidamos
+func (juego *Game) tomarEventos() []evento {
+	eventos := juego.eventos
+	juego.eventos = nil
+	return eventos
+}
+
+// Volvemos el jugador y su pelota al estado inicial y la campaña a su nivel inicial (la fuente de entrada se mantiene)
+func (juego *Game) reiniciar() {
+	control := juego.jugador.control
+	juego.jugador = juego.copiaJugador
+	juego.jugador.control = control
+	juego.efectos = efectos{}
+	juego.puntosNivel = make([]int, len(juego.niveles))
+
+	juego.prepararNivel(juego.nivelInicial)
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// Partida con la campaña incluida manejada por el bot
+func juegoPrueba(t testing.TB, semilla int64) *Game {
+	t.Helper()
+	niveles, err := elegirNiveles("", "")
+	if err != nil {
+		t.Fatal("Error carga niveles:", err)
+	}
+	juego := nuevoJuego(nil, niveles, semilla)
+	juego.jugador.control = entradaBot{juego}
+	return juego
+}
+
+// Agregamos pelotas lanzadas desde distintos puntos de la mitad de abajo hasta tener 'cantidad'
+func agregarPelotas(juego *Game, cantidad int) {
+	jugador := &juego.jugador
+	for i := 0; len(jugador.pelotas) < cantidad; i++ {
+		bola := juego.copiaPelota
+		bola.jugador = jugador
+		bola.pegada = false
+		bola.pos = pos{float32(40 + (i*37)%520), float32(450 + (i*53)%250)}
+		bola.posAnterior = bola.pos
+		bola.vel_x = float32(velocidades_x[i%len(velocidades_x)])
+		bola.vel_y = -10
+		jugador.pelotas = append(jugador.pelotas, bola)
+	}
+}
+
+// Con decenas de pelotas y los poderes que agregan pelotas, cada tick tiene que dejar la partida consistente.
+// Con 'go test -race' cubre tambien la fase en que se juntan los impactos y se aplican al muro
+func TestMuchasPelotas(t *testing.T) {
+	juego := juegoPrueba(t, 1)
+	maximo := 0
+
+	for tick := 0; tick < 4000 && juego.estado != win; tick++ {
+		if juego.estado == play {
+			// Cada tanto volvemos a llenar la partida de pelotas y las dividimos
+			if tick%300 == 0 {
+				agregarPelotas(juego, 40)
+			}
+			if tick%300 == 150 {
+				juego.activarPoder(poderDividir)
+			}
+		}
+
+		juego.Step()
+		verificarPartida(t, juego)
+		if t.Failed() {
+			t.Fatalf("tick %d: partida inconsistente", juego.tick)
+		}
+
+		if cantidad := len(juego.jugador.pelotas); cantidad > maximo {
+			maximo = cantidad
+		}
+	}
+
+	if maximo < 24 {
+		t.Errorf("hubo como maximo %d pelotas a la vez, se esperaban decenas", maximo)
+	}
+}
+
+// Verificamos que las pelotas esten dentro de la ventana y apunten al jugador y que el contador de ladrillos
+// coincida con el muro
+func verificarPartida(t *testing.T, juego *Game) {
+	t.Helper()
+	jugador := &juego.jugador
+
+	if len(jugador.pelotas) == 0 {
+		t.Errorf("el jugador se quedo sin pelotas")
+	}
+	for i, bola := range jugador.pelotas {
+		if bola.jugador != jugador {
+			t.Errorf("pelota %d: apunta a otro jugador", i)
+		}
+		if bola.radio <= 0 {
+			t.Errorf("pelota %d: radio %v", i, bola.radio)
+		}
+		if math.IsNaN(float64(bola.pos.x)) || math.IsNaN(float64(bola.pos.y)) {
+			t.Errorf("pelota %d: posicion %v", i, bola.pos)
+		}
+		if bola.pos.x < 0 || bola.pos.x > anchoVentana || bola.pos.y < 0 || bola.pos.y >= altoVentana {
+			t.Errorf("pelota %d: fuera de la ventana en %v", i, bola.pos)
+		}
+	}
+
+	if enPie := contarRestantes(juego.muro); juego.restantes != enPie {
+		t.Errorf("el contador dice %d ladrillos y en el muro hay %d", juego.restantes, enPie)
+	}
+}
+
+// Partida de un solo nivel con un ladrillo de resistencia 1, uno de oro y uno de resistencia 3
+func juegoContador(t *testing.T) *Game {
+	t.Helper()
+	unico, err := leerNivel("contador", strings.NewReader(`
+ancho 3
+alto 1
+ladrillo 50 20
+origen 300 200
+color 1 255 152 152 255
+color 2 255 84 84 255
+color 3 255 0 0 255
+muro
+1G3
+`))
+	if err != nil {
+		t.Fatal("Error nivel:", err)
+	}
+	juego := nuevoJuego(nil, []*nivel{unico}, 1)
+	juego.estado = play
+	return juego
+}
+
+// Los de oro no se pueden romper, asi que no cuentan para superar el nivel (ni al golpearlos)
+func TestRestantesSinOro(t *testing.T) {
+	juego := juegoContador(t)
+	if juego.restantes != 2 {
+		t.Fatalf("restantes = %d, se esperaban 2 (el de oro no cuenta)", juego.restantes)
+	}
+
+	juego.golpearLadrillo(1)
+	if juego.restantes != 2 || juego.muro[1].resist == 0 {
+		t.Errorf("golpear el de oro cambio el contador a %d (resistencia %d)", juego.restantes, juego.muro[1].resist)
+	}
+}
+
+// Un ladrillo de varios golpes solo descuenta cuando su resistencia llega a 0
+func TestRestantesVariosGolpes(t *testing.T) {
+	juego := juegoContador(t)
+
+	for golpe := 1; golpe <= 3; golpe++ {
+		juego.golpearLadrillo(2)
+
+		esperados := 2
+		if golpe == 3 {
+			esperados = 1
+		}
+		if juego.restantes != esperados {
+			t.Errorf("golpe %d: restantes = %d (resistencia %d), se esperaban %d", golpe, juego.restantes, juego.muro[2].resist, esperados)
+		}
+	}
+}
+
+// Romper el ultimo ladrillo deja el contador en 0 y supera el nivel con un unico evento
+func TestUltimoLadrilloSuperaNivel(t *testing.T) {
+	juego := juegoContador(t)
+
+	for i := 0; i < 3; i++ {
+		juego.golpearLadrillo(2)
+	}
+	juego.verificarVictoria()
+	if eventos := juego.tomarEventos(); len(eventos) != 0 {
+		t.Fatalf("eventos antes de romper el ultimo ladrillo: %v", eventos)
+	}
+
+	juego.golpearLadrillo(0)
+	if juego.restantes != 0 {
+		t.Fatalf("restantes = %d despues de romper el ultimo ladrillo", juego.restantes)
+	}
+	juego.verificarVictoria()
+
+	// La partida ya termino: los ticks siguientes no vuelven a avisar
+	for i := 0; i < 10; i++ {
+		juego.Step()
+	}
+
+	eventos := juego.tomarEventos()
+	if len(eventos) != 1 || eventos[0] != (evento{eventoNivelSuperado, 0}) {
+		t.Fatalf("eventos = %v, se esperaba un unico nivel superado", eventos)
+	}
+	if juego.estado != win {
+		t.Errorf("estado = %v, se esperaba win (era el unico nivel)", juego.estado)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+// ------------------------------------------------------------------------------------
+// -----------------------------TIPOS DE LADRILLO--------------------------------------
+// ------------------------------------------------------------------------------------
+
+// Tipo de ladrillo. Los normales tienen la resistencia del digito del nivel, los demas se marcan con su letra
+type tipoLadrillo int
+
+const (
+	ladrilloNormal    tipoLadrillo = iota
+	ladrilloOro                    // G: indestructible, no cuenta para superar el nivel
+	ladrilloPlata                  // S: necesita mas golpes en cada nivel de la campaña
+	ladrilloExplosivo              // X: al romperse golpea a los ladrillos vecinos
+	ladrilloRegenera               // R: vuelve a aparecer un rato despues de romperse
+	ladrilloMovil                  // M: se desliza de costado rebotando en las paredes y los otros ladrillos
+	cantidadTipos
+)
+
+// Datos de cada tipo especial: letra en el muro, color por defecto, resistencia y puntaje
+type datosTipo struct {
+	letra    byte
+	color    color
+	resist   int
+	extScore int
+}
+
+var tiposLadrillo = [cantidadTipos]datosTipo{
+	ladrilloNormal:    {'.', color{0, 0, 0, 0}, 0, 10},
+	ladrilloOro:       {'G', color{220, 180, 40, 255}, 1, 0},   // DORADO
+	ladrilloPlata:     {'S', color{190, 190, 200, 255}, 2, 50}, // PLATEADO
+	ladrilloExplosivo: {'X', color{255, 110, 0, 255}, 1, 20},   // NARANJA
+	ladrilloRegenera:  {'R', color{150, 60, 220, 255}, 1, 10},  // VIOLETA
+	ladrilloMovil:     {'M', color{60, 200, 120, 255}, 1, 20},  // VERDE
+}
+
+// Reglas de los ladrillos especiales
+const (
+	segundosRegenera = 6 // Segundos que tarda en volver un ladrillo regenerable
+	velocidadMovil   = 1 // Pixeles por fotograma de los ladrillos moviles
+)
+
+// Tipo de ladrillo que corresponde a la letra del muro
+func tipoPorLetra(letra byte) (tipoLadrillo, bool) {
+	for tipo := ladrilloOro; tipo < cantidadTipos; tipo++ {
+		if tiposLadrillo[tipo].letra == letra {
+			return tipo, true
+		}
+	}
+	return ladrilloNormal, false
+}
+
+// Golpes que aguanta un ladrillo plateado en el nivel indicado de la campaña (uno mas cada dos niveles)
+func resistenciaPlata(indiceNivel int) int {
+	return tiposLadrillo[ladrilloPlata].resist + indiceNivel/2
+}
+
+// Puntaje de un ladrillo plateado en el nivel indicado de la campaña
+func puntajePlata(indiceNivel int) int {
+	return tiposLadrillo[ladrilloPlata].extScore * (indiceNivel + 1)
+}
+
+// Metodo que indica si el ladrillo hay que romperlo para superar el nivel
+func (bloque *ladrillo) cuentaParaGanar() bool {
+	return bloque.tipo != ladrilloOro
+}
+
+// Cuando se rompe un ladrillo explosivo golpeamos una vez a cada vecino (los explosivos vecinos encadenan la explosion)
+func (juego *Game) explotar(indice int) {
+	for _, vecino := range juego.vecinos(indice) {
+		juego.golpearLadrillo(vecino)
+	}
+}
+
+// Ladrillos con resistencia pegados al indicado (los 8 alrededor en el muro)
+func (juego *Game) vecinos(indice int) []int {
+	bloque := juego.muro[indice]
+	alcance := pos{float32(bloque.ancho) + 2, float32(bloque.alto) + 2}
+	minimo := pos{bloque.pos.x - alcance.x, bloque.pos.y - alcance.y}
+	maximo := pos{bloque.pos.x + alcance.x, bloque.pos.y + alcance.y}
+
+	var vecinos []int
+	probarLadrillo := func(i int) {
+		if i == indice || juego.muro[i].resist == 0 {
+			return
+		}
+		otro := juego.muro[i]
+		if abs32(otro.pos.x-bloque.pos.x) <= float32(bloque.ancho+otro.ancho)/2+1.5 &&
+			abs32(otro.pos.y-bloque.pos.y) <= float32(bloque.alto+otro.alto)/2+1.5 {
+			vecinos = append(vecinos, i)
+		}
+	}
+
+	if juego.grilla != nil {
+		for _, i := range juego.grilla.consultar(minimo, maximo) {
+			probarLadrillo(i)
+		}
+	} else {
+		for i := range juego.muro {
+			probarLadrillo(i)
+		}
+	}
+
+	return vecinos
+}
+
+// Fase de actualizacion de los ladrillos especiales: los moviles avanzan y los regenerables rotos descuentan su tiempo
+func (juego *Game) actualizarLadrillos() {
+	for i := range juego.muro {
+		bloque := &juego.muro[i]
+		if bloque.resist == 0 && bloque.tipo == ladrilloRegenera {
+			juego.regenerar(i)
+		}
+		if bloque.resist > 0 && bloque.tipo == ladrilloMovil {
+			juego.moverLadrillo(i)
+		}
+	}
+}
+
+// El ladrillo regenerable vuelve con su resistencia y color originales cuando se cumple el tiempo,
+// siempre que no haya una pelota encima (si la hay espera al proximo tick)
+func (juego *Game) regenerar(indice int) {
+	bloque := &juego.muro[indice]
+	bloque.regenera -= juego.dt
+	if bloque.regenera > 0 {
+		return
+	}
+
+	for _, bola := range juego.jugador.pelotas {
+		if abs32(bola.pos.x-bloque.pos.x) <= float32(bloque.ancho)/2+bola.radio &&
+			abs32(bola.pos.y-bloque.pos.y) <= float32(bloque.alto)/2+bola.radio {
+			return
+		}
+	}
+
+	bloque.resist = juego.copiaMuro[indice].resist
+	bloque.color = juego.copiaMuro[indice].color
+	bloque.regenera = 0
+	if bloque.cuentaParaGanar() {
+		juego.restantes++
+	}
+}
+
+// El ladrillo movil avanza de costado y da la vuelta si va a tocar una pared u otro ladrillo con resistencia
+func (juego *Game) moverLadrillo(indice int) {
+	bloque := &juego.muro[indice]
+	anterior := *bloque
+
+	siguiente := anterior
+	siguiente.pos.x += bloque.vel_x * juego.dt * frecuenciaBase
+
+	desde, hasta := bordesLadrillo(siguiente)
+	choca := desde.x < 0 || hasta.x > float32(anchoVentana)
+	if !choca {
+		probarLadrillo := func(i int) {
+			if i == indice || juego.muro[i].resist == 0 {
+				return
+			}
+			otroDesde, otroHasta := bordesLadrillo(juego.muro[i])
+			if desde.x < otroHasta.x && hasta.x > otroDesde.x && desde.y < otroHasta.y && hasta.y > otroDesde.y {
+				choca = true
+			}
+		}
+		if juego.grilla != nil {
+			for _, i := range juego.grilla.consultar(desde, hasta) {
+				probarLadrillo(i)
+			}
+		} else {
+			for i := range juego.muro {
+				probarLadrillo(i)
+			}
+		}
+	}
+
+	if choca {
+		bloque.vel_x = -bloque.vel_x
+		return
+	}
+
+	bloque.pos = siguiente.pos
+	if juego.grilla != nil {
+		juego.grilla.mover(indice, anterior, *bloque)
+	}
+}
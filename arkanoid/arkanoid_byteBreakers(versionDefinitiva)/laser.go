@@ -0,0 +1,116 @@
+package main
+
+// ------------------------------------------------------------------------------------
+// -----------------------------------LASER--------------------------------------------
+// ------------------------------------------------------------------------------------
+
+// Reglas de los disparos de la barra con el poder laser
+const (
+	maxDisparos      = 6    // Disparos en pantalla a la vez
+	cadenciaLaser    = 0.25 // Segundos minimos entre dos rafagas
+	velocidadDisparo = 12   // Pixeles por fotograma (hacia arriba)
+	anchoDisparo     = 2
+	altoDisparo      = 10
+)
+
+// Disparo del laser: sube en linea recta hasta tocar un ladrillo o salir por arriba
+type disparo struct {
+	pos   pos
+	ancho int
+	alto  int
+	vel_y float32
+	color color
+}
+
+// Metodo para dibujar el disparo
+func (disparo *disparo) Dibujar(ventana []byte) {
+	startX := disparo.pos.x - float32(disparo.ancho)/2
+	startY := disparo.pos.y - float32(disparo.alto)/2
+
+	for y := 0; y < disparo.alto; y++ {
+		for x := 0; x < disparo.ancho; x++ {
+			colorear(pos{startX + float32(x), startY + float32(y)}, disparo.color, ventana)
+		}
+	}
+}
+
+// Metodo que hace subir el disparo
+func (disparo *disparo) Movimiento(dt float32) {
+	disparo.pos.y -= disparo.vel_y * dt * frecuenciaBase
+}
+
+// Si la barra tiene el laser y el jugador dispara, sale un disparo de cada punta de la barra
+// (respetando la cadencia y el tope de disparos en pantalla). Despues movemos los disparos y resolvemos sus impactos
+func (juego *Game) actualizarLaser() {
+	jugador := &juego.jugador
+
+	if juego.esperaLaser > 0 {
+		juego.esperaLaser -= juego.dt
+	}
+
+	if jugador.laser && jugador.entrada.disparar && juego.esperaLaser <= 0 && len(juego.disparos)+2 <= maxDisparos {
+		for _, lado := range []float32{-1, 1} {
+			juego.disparos = append(juego.disparos, disparo{
+				pos:   pos{jugador.pos.x + lado*(float32(jugador.ancho)/2-5), jugador.pos.y - float32(jugador.alto+altoDisparo)/2},
+				ancho: anchoDisparo,
+				alto:  altoDisparo,
+				vel_y: velocidadDisparo,
+				color: color{255, 60, 60, 255}, // ROJO
+			})
+		}
+		juego.esperaLaser = cadenciaLaser
+	}
+
+	enJuego := juego.disparos[:0]
+	for _, disparo := range juego.disparos {
+		anterior := disparo.pos
+		llamarMovimiento(&disparo, juego.dt)
+
+		if indice, ok := juego.ladrilloEnRecorrido(anterior, disparo); ok {
+			juego.golpearLadrillo(indice)
+			continue
+		}
+		if disparo.pos.y+float32(disparo.alto)/2 > 0 {
+			enJuego = append(enJuego, disparo)
+		}
+	}
+	juego.disparos = enJuego
+}
+
+// Primer ladrillo con resistencia que toca el disparo al subir desde 'anterior' hasta su posicion actual
+// (el de mas abajo, que es el primero que encuentra)
+func (juego *Game) ladrilloEnRecorrido(anterior pos, disparo disparo) (int, bool) {
+	mitadAncho := float32(disparo.ancho) / 2
+	mitadAlto := float32(disparo.alto) / 2
+	minimo := pos{disparo.pos.x - mitadAncho, disparo.pos.y - mitadAlto}
+	maximo := pos{disparo.pos.x + mitadAncho, anterior.y + mitadAlto}
+
+	elegido, encontrado := 0, false
+	var fondoElegido float32
+	probarLadrillo := func(i int) {
+		if juego.muro[i].resist == 0 {
+			return
+		}
+
+		superior, inferior := bordesLadrillo(juego.muro[i])
+		if maximo.x < superior.x || minimo.x > inferior.x || maximo.y < superior.y || minimo.y > inferior.y {
+			return
+		}
+		if !encontrado || inferior.y > fondoElegido {
+			elegido, encontrado, fondoElegido = i, true, inferior.y
+		}
+	}
+
+	// Igual que las pelotas: con grilla solo las celdas del recorrido, sin grilla todo el muro
+	if juego.grilla != nil {
+		for _, i := range juego.grilla.consultar(minimo, maximo) {
+			probarLadrillo(i)
+		}
+	} else {
+		for i := range juego.muro {
+			probarLadrillo(i)
+		}
+	}
+
+	return elegido, encontrado
+}
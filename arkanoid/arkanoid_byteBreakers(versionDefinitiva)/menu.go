@@ -0,0 +1,68 @@
+package main
+
+// ------------------------------------------------------------------------------------
+// -----------------------------------MENUS--------------------------------------------
+// ------------------------------------------------------------------------------------
+
+// Pantallas de la interfaz: la partida y los menus que se muestran sobre ella o en su lugar
+type tipoPantalla int
+
+const (
+	pantallaMenuPrincipal tipoPantalla = iota
+	pantallaJuego
+	pantallaPausa
+	pantallaOpciones
+	pantallaSalir
+)
+
+// Opcion de un menu. Con ENTER se ejecuta 'accion'; si tiene 'cambiar' las flechas laterales modifican
+// su valor, que se muestra al lado del texto con 'valor'
+type opcionMenu struct {
+	texto   string
+	valor   func() string
+	accion  func()
+	cambiar func(delta int)
+}
+
+// Menu generico: un titulo y una lista de opciones con una seleccionada. Lo usan todas las pantallas de menu
+type menu struct {
+	titulo    string
+	opciones  []opcionMenu
+	seleccion int
+}
+
+// Metodo que mueve la seleccion 'delta' opciones (da la vuelta en los extremos)
+func (menu *menu) mover(delta int) {
+	if len(menu.opciones) == 0 {
+		return
+	}
+	menu.seleccion = ((menu.seleccion+delta)%len(menu.opciones) + len(menu.opciones)) % len(menu.opciones)
+}
+
+// Metodo que ejecuta la accion de la opcion seleccionada
+func (menu *menu) elegir() {
+	if len(menu.opciones) == 0 {
+		return
+	}
+	if accion := menu.opciones[menu.seleccion].accion; accion != nil {
+		accion()
+	}
+}
+
+// Metodo que cambia el valor de la opcion seleccionada (si se puede cambiar)
+func (menu *menu) cambiar(delta int) {
+	if len(menu.opciones) == 0 {
+		return
+	}
+	if cambiar := menu.opciones[menu.seleccion].cambiar; cambiar != nil {
+		cambiar(delta)
+	}
+}
+
+// Metodo que devuelve el texto de la opcion como se muestra en pantalla (con su valor si tiene)
+func (opcion opcionMenu) etiqueta() string {
+	if opcion.valor == nil {
+		return opcion.texto
+	}
+	return opcion.texto + ": < " + opcion.valor() + " >"
+}
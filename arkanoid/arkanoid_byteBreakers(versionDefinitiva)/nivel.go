@@ -0,0 +1,415 @@
+package main
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ------------------------------------------------------------------------------------
+// -----------------------------------NIVELES------------------------------------------
+// ------------------------------------------------------------------------------------
+
+// Formato de los archivos de nivel (texto plano, '#' comenta hasta el final de la linea):
+//
+//	ancho 9                 -> cantidad de ladrillos por fila
+//	alto 17                 -> cantidad de filas
+//	ladrillo 50 20          -> ancho y alto de cada ladrillo en pixeles
+//	origen 300 200          -> centro del muro en la ventana
+//	color 1 255 152 152 255 -> color r g b a de los ladrillos con esa resistencia (una linea por resistencia).
+//	                           Con a menor a 255 el ladrillo deja ver el fondo
+//	color G 220 180 40 255  -> color de un tipo especial de ladrillo (opcional, cada tipo tiene uno por defecto)
+//	jefe 20 140 100 300 200 -> (opcional) nivel con jefe: vida, ancho, alto y centro. Se supera derrotandolo
+//	muro                    -> a partir de aca van 'alto' filas de 'ancho' caracteres
+//	.12345GSXRM             -> '.' o '0' sin ladrillo, '1' a '9' resistencia del ladrillo o la letra de un tipo especial:
+//	                           G oro (indestructible), S plata, X explosivo, R regenerable, M movil
+//
+// Toda resistencia usada en el muro necesita su color. Si no se define el color 0 es negro transparente
+
+// Niveles que vienen con el juego
+//
+//go:embed niveles/*.txt
+var nivelesIncluidos embed.FS
+
+// Campaña que se juega si no se indica otra: lista de niveles incluidos en orden
+const listaPorDefecto = "niveles/orden.txt"
+
+// Nivel leido de un archivo, listo para diagramar el muro
+type nivel struct {
+	nombre        string
+	ancho         int // Ladrillos por fila
+	alto          int // Cantidad de filas
+	anchoLadrillo int
+	altoLadrillo  int
+	origen        pos
+	resistencias  []byte         // ancho*alto resistencias, fila por fila
+	tipos         []tipoLadrillo // ancho*alto tipos, fila por fila
+	colores       map[int]color
+	coloresTipo   map[tipoLadrillo]color
+	jefe          *datosJefe // nil si el nivel no tiene jefe
+	huella        uint64     // Huella del contenido del archivo: identifica al nivel en las repeticiones
+}
+
+// Error de validacion de un nivel con el archivo, la linea y la columna donde esta el problema
+type errorNivel struct {
+	archivo string
+	linea   int
+	columna int
+	mensaje string
+}
+
+func (e *errorNivel) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.archivo, e.linea, e.columna, e.mensaje)
+}
+
+// Cargamos un nivel desde un archivo del disco
+func cargarNivel(ruta string) (*nivel, error) {
+	archivo, err := os.Open(ruta)
+	if err != nil {
+		return nil, err
+	}
+	defer archivo.Close()
+
+	return leerNivel(ruta, archivo)
+}
+
+// Cargamos uno de los niveles incluidos en el juego
+func cargarNivelIncluido(nombre string) (*nivel, error) {
+	archivo, err := nivelesIncluidos.Open(nombre)
+	if err != nil {
+		return nil, err
+	}
+	defer archivo.Close()
+
+	return leerNivel(nombre, archivo)
+}
+
+// Cargamos todos los niveles incluidos en el juego, esten o no en la campaña por defecto
+func cargarNivelesIncluidos() ([]*nivel, error) {
+	nombres, err := fs.Glob(nivelesIncluidos, path.Join(path.Dir(listaPorDefecto), "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+
+	var niveles []*nivel
+	for _, nombre := range nombres {
+		if nombre == listaPorDefecto {
+			continue
+		}
+		incluido, err := cargarNivelIncluido(nombre)
+		if err != nil {
+			return nil, err
+		}
+		niveles = append(niveles, incluido)
+	}
+	return niveles, nil
+}
+
+// Elegimos los niveles a jugar: un unico nivel si se indica su archivo, si no la campaña del archivo indicado
+// (un nivel por linea, con rutas relativas al archivo) o, si tampoco, la campaña incluida
+func elegirNiveles(rutaLista, rutaNivel string) ([]*nivel, error) {
+	if rutaNivel != "" {
+		unico, err := cargarNivel(rutaNivel)
+		if err != nil {
+			return nil, err
+		}
+		return []*nivel{unico}, nil
+	}
+
+	if rutaLista == "" {
+		archivo, err := nivelesIncluidos.Open(listaPorDefecto)
+		if err != nil {
+			return nil, err
+		}
+		defer archivo.Close()
+
+		return leerListaNiveles(listaPorDefecto, archivo, func(nombre string) (*nivel, error) {
+			return cargarNivelIncluido(path.Join(path.Dir(listaPorDefecto), nombre))
+		})
+	}
+
+	archivo, err := os.Open(rutaLista)
+	if err != nil {
+		return nil, err
+	}
+	defer archivo.Close()
+
+	return leerListaNiveles(rutaLista, archivo, func(nombre string) (*nivel, error) {
+		if !filepath.IsAbs(nombre) {
+			nombre = filepath.Join(filepath.Dir(rutaLista), nombre)
+		}
+		return cargarNivel(nombre)
+	})
+}
+
+// Nombre con el que guardamos el progreso de la campaña elegida
+func nombreCampania(rutaLista, rutaNivel string) string {
+	if rutaNivel != "" {
+		return rutaNivel
+	}
+	if rutaLista != "" {
+		return rutaLista
+	}
+	return "incluida"
+}
+
+// Leemos la lista de niveles de una campaña y cargamos cada uno con 'cargar'
+func leerListaNiveles(archivo string, lector io.Reader, cargar func(nombre string) (*nivel, error)) ([]*nivel, error) {
+	var niveles []*nivel
+
+	escaner := bufio.NewScanner(lector)
+	numeroLinea := 0
+	for escaner.Scan() {
+		numeroLinea++
+		campos := camposLinea(escaner.Text())
+		if len(campos) == 0 {
+			continue
+		}
+		if len(campos) > 1 {
+			return nil, &errorNivel{archivo, numeroLinea, campos[1].columna, "se espera un unico archivo de nivel por linea"}
+		}
+
+		cargado, err := cargar(campos[0].texto)
+		if err != nil {
+			return nil, err
+		}
+		niveles = append(niveles, cargado)
+	}
+
+	if err := escaner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", archivo, err)
+	}
+	if len(niveles) == 0 {
+		return nil, &errorNivel{archivo, numeroLinea + 1, 1, "la campaña no tiene niveles"}
+	}
+
+	return niveles, nil
+}
+
+// Palabra de una linea junto con la columna (desde 1) donde empieza
+type campo struct {
+	texto   string
+	columna int
+}
+
+// Separamos la linea en palabras ignorando el comentario
+func camposLinea(linea string) []campo {
+	if comentario := strings.IndexByte(linea, '#'); comentario >= 0 {
+		linea = linea[:comentario]
+	}
+
+	var campos []campo
+	inicio := -1
+	for i := 0; i <= len(linea); i++ {
+		separador := i == len(linea) || linea[i] == ' ' || linea[i] == '\t' || linea[i] == '\r'
+		if separador && inicio >= 0 {
+			campos = append(campos, campo{linea[inicio:i], inicio + 1})
+			inicio = -1
+		} else if !separador && inicio < 0 {
+			inicio = i
+		}
+	}
+	return campos
+}
+
+// Leemos y validamos un nivel. 'archivo' es solo el nombre que aparece en los errores
+func leerNivel(archivo string, lector io.Reader) (*nivel, error) {
+	nivel := &nivel{nombre: archivo, colores: map[int]color{0: {0, 0, 0, 0}}, coloresTipo: make(map[tipoLadrillo]color)}
+	for tipo := ladrilloOro; tipo < cantidadTipos; tipo++ {
+		nivel.coloresTipo[tipo] = tiposLadrillo[tipo].color
+	}
+	definidos := make(map[string]bool)
+
+	// Todo lo que se lee pasa por la huella
+	huella := fnv.New64a()
+	lector = io.TeeReader(lector, huella)
+
+	fallo := func(linea, columna int, formato string, args ...interface{}) error {
+		return &errorNivel{archivo, linea, columna, fmt.Sprintf(formato, args...)}
+	}
+
+	// Convierte los argumentos de una clave a enteros entre minimo y maximo
+	enteros := func(linea int, clave campo, argumentos []campo, cantidad, minimo, maximo int) ([]int, error) {
+		if len(argumentos) != cantidad {
+			return nil, fallo(linea, clave.columna, "%q espera %d valores y tiene %d", clave.texto, cantidad, len(argumentos))
+		}
+		valores := make([]int, cantidad)
+		for i, argumento := range argumentos {
+			valor, err := strconv.Atoi(argumento.texto)
+			if err != nil {
+				return nil, fallo(linea, argumento.columna, "%q no es un numero entero", argumento.texto)
+			}
+			if valor < minimo || valor > maximo {
+				return nil, fallo(linea, argumento.columna, "%d fuera de rango (%d a %d)", valor, minimo, maximo)
+			}
+			valores[i] = valor
+		}
+		return valores, nil
+	}
+
+	escaner := bufio.NewScanner(lector)
+	numeroLinea := 0
+	enMuro := false
+	lineaMuro := 0
+	fila := 0
+
+	for escaner.Scan() {
+		numeroLinea++
+		campos := camposLinea(escaner.Text())
+
+		// Filas del muro
+		if enMuro {
+			if len(campos) == 0 {
+				continue
+			}
+			if fila == nivel.alto {
+				return nil, fallo(numeroLinea, campos[0].columna, "el muro tiene mas de %d filas", nivel.alto)
+			}
+			if len(campos) > 1 {
+				return nil, fallo(numeroLinea, campos[1].columna, "la fila del muro no puede tener espacios")
+			}
+
+			texto := campos[0].texto
+			for i := 0; i < len(texto); i++ {
+				columna := campos[0].columna + i
+				if i >= nivel.ancho {
+					return nil, fallo(numeroLinea, columna, "la fila tiene mas de %d ladrillos", nivel.ancho)
+				}
+
+				resistencia := 0
+				tipo := ladrilloNormal
+				switch {
+				case texto[i] == '.':
+				case texto[i] >= '0' && texto[i] <= '9':
+					resistencia = int(texto[i] - '0')
+				default:
+					especial, ok := tipoPorLetra(texto[i])
+					if !ok {
+						return nil, fallo(numeroLinea, columna, "caracter %q invalido (se espera '.', un digito o G, S, X, R, M)", texto[i])
+					}
+					tipo = especial
+				}
+
+				if _, ok := nivel.colores[resistencia]; !ok {
+					return nil, fallo(numeroLinea, columna, "la resistencia %d no tiene color definido", resistencia)
+				}
+				nivel.resistencias = append(nivel.resistencias, byte(resistencia))
+				nivel.tipos = append(nivel.tipos, tipo)
+			}
+			if len(texto) < nivel.ancho {
+				return nil, fallo(numeroLinea, campos[0].columna+len(texto), "la fila tiene %d ladrillos y se esperan %d", len(texto), nivel.ancho)
+			}
+
+			fila++
+			continue
+		}
+
+		if len(campos) == 0 {
+			continue
+		}
+
+		// Encabezado
+		clave := campos[0]
+		argumentos := campos[1:]
+
+		if clave.texto != "color" && definidos[clave.texto] {
+			return nil, fallo(numeroLinea, clave.columna, "%q definido dos veces", clave.texto)
+		}
+		definidos[clave.texto] = true
+
+		switch clave.texto {
+		case "ancho", "alto":
+			valores, err := enteros(numeroLinea, clave, argumentos, 1, 1, 1000)
+			if err != nil {
+				return nil, err
+			}
+			if clave.texto == "ancho" {
+				nivel.ancho = valores[0]
+			} else {
+				nivel.alto = valores[0]
+			}
+
+		case "ladrillo":
+			valores, err := enteros(numeroLinea, clave, argumentos, 2, 1, anchoVentana)
+			if err != nil {
+				return nil, err
+			}
+			nivel.anchoLadrillo, nivel.altoLadrillo = valores[0], valores[1]
+
+		case "origen":
+			valores, err := enteros(numeroLinea, clave, argumentos, 2, -10000, 10000)
+			if err != nil {
+				return nil, err
+			}
+			nivel.origen = pos{float32(valores[0]), float32(valores[1])}
+
+		case "color":
+			// Color de un tipo especial: la letra en lugar de la resistencia
+			if len(argumentos) > 0 && len(argumentos[0].texto) == 1 {
+				if tipo, ok := tipoPorLetra(argumentos[0].texto[0]); ok {
+					valores, err := enteros(numeroLinea, clave, argumentos[1:], 4, 0, 255)
+					if err != nil {
+						return nil, err
+					}
+					nivel.coloresTipo[tipo] = color{byte(valores[0]), byte(valores[1]), byte(valores[2]), byte(valores[3])}
+					continue
+				}
+			}
+
+			valores, err := enteros(numeroLinea, clave, argumentos, 5, 0, 255)
+			if err != nil {
+				return nil, err
+			}
+			if valores[0] > 9 {
+				return nil, fallo(numeroLinea, argumentos[0].columna, "la resistencia %d no entra en un caracter (0 a 9)", valores[0])
+			}
+			nivel.colores[valores[0]] = color{byte(valores[1]), byte(valores[2]), byte(valores[3]), byte(valores[4])}
+
+		case "jefe":
+			valores, err := enteros(numeroLinea, clave, argumentos, 5, 1, 10000)
+			if err != nil {
+				return nil, err
+			}
+			if valores[1] > anchoVentana || valores[2] > altoVentana {
+				return nil, fallo(numeroLinea, argumentos[1].columna, "el jefe no entra en la ventana (%dx%d)", anchoVentana, altoVentana)
+			}
+			nivel.jefe = &datosJefe{vida: valores[0], ancho: valores[1], alto: valores[2], pos: pos{float32(valores[3]), float32(valores[4])}}
+
+		case "muro":
+			if len(argumentos) > 0 {
+				return nil, fallo(numeroLinea, argumentos[0].columna, "\"muro\" no lleva valores")
+			}
+			for _, requerido := range []string{"ancho", "alto", "ladrillo", "origen"} {
+				if !definidos[requerido] {
+					return nil, fallo(numeroLinea, clave.columna, "falta %q antes del muro", requerido)
+				}
+			}
+			enMuro = true
+			lineaMuro = numeroLinea
+
+		default:
+			return nil, fallo(numeroLinea, clave.columna, "clave desconocida %q", clave.texto)
+		}
+	}
+
+	if err := escaner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", archivo, err)
+	}
+	if !enMuro {
+		return nil, fallo(numeroLinea+1, 1, "falta la seccion \"muro\"")
+	}
+	if fila < nivel.alto {
+		return nil, fallo(numeroLinea+1, 1, "el muro (linea %d) tiene %d filas y se esperan %d", lineaMuro, fila, nivel.alto)
+	}
+
+	nivel.huella = huella.Sum64()
+	return nivel, nil
+}
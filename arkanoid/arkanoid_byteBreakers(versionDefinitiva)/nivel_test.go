@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Encabezado valido de un muro de 3x2 con colores para las resistencias 0 a 3 (lineas 1 a 8)
+const encabezadoPrueba = `ancho 3
+alto 2
+ladrillo 50 20
+origen 300 200
+color 1 255 152 152 255
+color 2 255 84 84 255
+color 3 255 0 0 255
+muro
+`
+
+func TestLeerNivel(t *testing.T) {
+	unico, err := leerNivel("nivel.txt", strings.NewReader(encabezadoPrueba+"123\n.G1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unico.ancho != 3 || unico.alto != 2 || len(unico.resistencias) != 6 || unico.tipos[4] != ladrilloOro {
+		t.Errorf("nivel leido mal: %+v", unico)
+	}
+}
+
+// Cada error tiene que indicar el archivo, la linea y la columna exactas del problema
+func TestLeerNivelErrores(t *testing.T) {
+	casos := []struct {
+		nombre string
+		texto  string
+		error  string
+	}{
+		{"clave desconocida", "ancho 3\n  velocidad 4\n", `nivel.txt:2:3: clave desconocida "velocidad"`},
+		{"clave repetida", "ancho 3\nancho 4\n", `nivel.txt:2:1: "ancho" definido dos veces`},
+		{"valor que no es numero", "ancho tres\n", `nivel.txt:1:7: "tres" no es un numero entero`},
+		{"cantidad de valores", "origen 300\n", `nivel.txt:1:1: "origen" espera 2 valores y tiene 1`},
+		{"muro demasiado ancho", "ancho 1001\n", "nivel.txt:1:7: 1001 fuera de rango (1 a 1000)"},
+		{"muro demasiado alto", "ancho 3\nalto   5000\n", "nivel.txt:2:8: 5000 fuera de rango (1 a 1000)"},
+		{"ladrillo mas ancho que la ventana", "ladrillo 601 20\n", "nivel.txt:1:10: 601 fuera de rango (1 a 600)"},
+		{"falta una clave antes del muro", "ancho 3\nalto 2\nmuro\n", `nivel.txt:3:1: falta "ladrillo" antes del muro`},
+		{"fila mas corta", encabezadoPrueba + "123\n12\n", "nivel.txt:10:3: la fila tiene 2 ladrillos y se esperan 3"},
+		{"fila mas larga", encabezadoPrueba + "123\n  1231\n", "nivel.txt:10:6: la fila tiene mas de 3 ladrillos"},
+		{"fila con espacios", encabezadoPrueba + "1 23\n", "nivel.txt:9:3: la fila del muro no puede tener espacios"},
+		{"caracter desconocido", encabezadoPrueba + "1Z1\n", `nivel.txt:9:2: caracter 'Z' invalido (se espera '.', un digito o G, S, X, R, M)`},
+		{"resistencia sin color", encabezadoPrueba + "123\n..9\n", "nivel.txt:10:3: la resistencia 9 no tiene color definido"},
+		{"filas de mas", encabezadoPrueba + "123\n123\n123\n", "nivel.txt:11:1: el muro tiene mas de 2 filas"},
+		{"filas de menos", encabezadoPrueba + "123\n", "nivel.txt:10:1: el muro (linea 8) tiene 1 filas y se esperan 2"},
+		{"sin muro", "ancho 3\n", `nivel.txt:2:1: falta la seccion "muro"`},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			_, err := leerNivel("nivel.txt", strings.NewReader(caso.texto))
+			if err == nil {
+				t.Fatalf("se esperaba el error %q", caso.error)
+			}
+			var errNivel *errorNivel
+			if !errors.As(err, &errNivel) {
+				t.Fatalf("el error %q no es un errorNivel", err)
+			}
+			if err.Error() != caso.error {
+				t.Errorf("error %q, se esperaba %q", err, caso.error)
+			}
+		})
+	}
+}
@@ -0,0 +1,416 @@
+package main
+
+// ------------------------------------------------------------------------------------
+// -----------------------------------PODERES------------------------------------------
+// ------------------------------------------------------------------------------------
+
+// Poderes que caen en capsulas de los ladrillos destruidos
+type poder int
+
+const (
+	poderExpandir poder = iota // E: barra mas ancha
+	poderLento                 // S: pelotas mas lentas
+	poderAtrapar               // C: la pelota se pega en la barra
+	poderLaser                 // L: la barra dispara
+	poderDividir               // D: cada pelota se divide en tres
+	poderSalida                // B: se abre la salida al siguiente nivel
+	poderVida                  // P: vida extra
+	cantidadPoderes
+)
+
+// Datos de cada poder: letra y color de la capsula y cuantos segundos dura (0 si es instantaneo)
+type datosPoder struct {
+	letra    byte
+	color    color
+	duracion float32
+}
+
+var poderes = [cantidadPoderes]datosPoder{
+	poderExpandir: {'E', color{40, 90, 255, 255}, 15},  // AZUL
+	poderLento:    {'S', color{255, 150, 0, 255}, 10},  // NARANJA
+	poderAtrapar:  {'C', color{40, 200, 40, 255}, 15},  // VERDE
+	poderLaser:    {'L', color{230, 30, 30, 255}, 15},  // ROJO
+	poderDividir:  {'D', color{0, 220, 220, 255}, 0},   // CELESTE
+	poderSalida:   {'B', color{255, 80, 200, 255}, 10}, // ROSA
+	poderVida:     {'P', color{160, 160, 160, 255}, 0}, // GRIS
+}
+
+// Reglas de las capsulas y los poderes
+const (
+	probabilidadCapsula = 0.15 // Probabilidad de que un ladrillo destruido suelte una capsula
+	maxCapsulas         = 3    // Capsulas cayendo a la vez
+	velocidadCapsula    = 3    // Pixeles por fotograma
+	anchoCapsula        = 30
+	altoCapsula         = 12
+	extraExpandir       = 50  // Pixeles que se agregan al ancho de la barra
+	factorLento         = 0.6 // Fraccion de la velocidad de las pelotas con el poder lento
+	maxPelotas          = 12  // Tope de pelotas al dividir
+	desvioDividir       = 4   // Diferencia de vel_x entre las pelotas divididas
+	puntosSalida        = 100 // Puntos por salir del nivel por la salida
+	maxVidas            = 9
+	segundosPegada      = 3 // Segundos que la pelota queda pegada antes de lanzarse sola
+	periodoApunte       = 2 // Segundos que tarda la pelota apoyada en ir y volver sobre la barra
+)
+
+// Capsula que cae hasta que la barra la atrapa o se pierde por abajo
+type capsula struct {
+	pos   pos
+	ancho int
+	alto  int
+	vel_y float32
+	poder poder
+}
+
+// Metodo para dibujar la capsula con la letra de su poder
+func (capsula *capsula) Dibujar(ventana []byte) {
+	graficarIconoPoder(capsula.poder, capsula.pos, capsula.ancho, capsula.alto, ventana)
+}
+
+// Metodo que hace caer la capsula
+func (capsula *capsula) Movimiento(dt float32) {
+	capsula.pos.y += capsula.vel_y * dt * frecuenciaBase
+}
+
+// Poderes activos de la partida
+type efectos struct {
+	restante   [cantidadPoderes]float32 // Segundos que le quedan a cada poder con duracion (0 si no esta activo)
+	anchoExtra int                      // Ancho que se le agrego a la barra al expandirla
+}
+
+// Metodo que indica si el poder esta activo
+func (efectos *efectos) activo(p poder) bool {
+	return efectos.restante[p] > 0
+}
+
+// Si el ladrillo destruido suelta una capsula, elegimos el poder al azar (con el azar de la partida para poder repetirla)
+func (juego *Game) soltarCapsula(ladrillo *ladrillo) {
+	if len(juego.capsulas) >= maxCapsulas || juego.azar.Float64() >= probabilidadCapsula {
+		return
+	}
+
+	juego.capsulas = append(juego.capsulas, capsula{
+		pos:   ladrillo.pos,
+		ancho: anchoCapsula,
+		alto:  altoCapsula,
+		vel_y: velocidadCapsula,
+		poder: poder(juego.azar.Intn(int(cantidadPoderes))),
+	})
+}
+
+// Movemos las capsulas: las que toca la barra activan su poder y las que pasan el borde inferior se pierden
+func (juego *Game) actualizarCapsulas() {
+	jugador := &juego.jugador
+
+	enJuego := juego.capsulas[:0]
+	for _, capsula := range juego.capsulas {
+		llamarMovimiento(&capsula, juego.dt)
+
+		atrapada := abs32(capsula.pos.x-jugador.pos.x) <= float32(capsula.ancho+jugador.ancho)/2 &&
+			abs32(capsula.pos.y-jugador.pos.y) <= float32(capsula.alto+jugador.alto)/2
+
+		if atrapada {
+			juego.activarPoder(capsula.poder)
+		} else if capsula.pos.y-float32(capsula.alto)/2 < float32(altoVentana) {
+			enJuego = append(enJuego, capsula)
+		}
+	}
+	juego.capsulas = enJuego
+}
+
+// Activamos el poder. Expandir, atrapar y laser son modos de la barra: activar uno cancela los otros dos.
+// Volver a atrapar un poder que ya esta activo renueva su duracion sin acumular el efecto
+func (juego *Game) activarPoder(p poder) {
+	jugador := &juego.jugador
+
+	switch p {
+	case poderExpandir, poderAtrapar, poderLaser:
+		for _, modo := range []poder{poderExpandir, poderAtrapar, poderLaser} {
+			if modo != p {
+				juego.desactivarPoder(modo)
+			}
+		}
+
+	case poderDividir:
+		juego.dividirPelotas()
+
+	case poderVida:
+		if jugador.vida < maxVidas {
+			jugador.vida++
+		}
+	}
+
+	if p == poderExpandir && !juego.efectos.activo(poderExpandir) {
+		juego.efectos.anchoExtra = extraExpandir
+		jugador.ancho += extraExpandir
+	}
+
+	juego.efectos.restante[p] = poderes[p].duracion
+	jugador.atrapa = juego.efectos.activo(poderAtrapar)
+	jugador.laser = juego.efectos.activo(poderLaser)
+}
+
+// Desactivamos el poder deshaciendo su efecto
+func (juego *Game) desactivarPoder(p poder) {
+	if !juego.efectos.activo(p) {
+		return
+	}
+	juego.efectos.restante[p] = 0
+
+	jugador := &juego.jugador
+	switch p {
+	case poderExpandir:
+		jugador.ancho -= juego.efectos.anchoExtra
+		juego.efectos.anchoExtra = 0
+	case poderAtrapar:
+		jugador.atrapa = false
+		juego.soltarPelotas()
+	case poderLaser:
+		jugador.laser = false
+		juego.esperaLaser = 0
+	}
+}
+
+// Descontamos el tiempo de los poderes activos y desactivamos los que se terminan
+func (juego *Game) actualizarPoderes() {
+	for p := poder(0); p < cantidadPoderes; p++ {
+		if !juego.efectos.activo(p) {
+			continue
+		}
+		juego.efectos.restante[p] -= juego.dt
+		if juego.efectos.restante[p] <= 0 {
+			juego.efectos.restante[p] = juego.dt // Para que desactivarPoder lo vea activo
+			juego.desactivarPoder(p)
+		}
+	}
+}
+
+// Con la salida abierta, llevar la barra contra la pared derecha pasa de nivel
+func (juego *Game) verificarSalida() {
+	jugador := &juego.jugador
+	if juego.estado == play && juego.efectos.activo(poderSalida) && jugador.pos.x+float32(jugador.ancho)/2 >= float32(anchoVentana)-1 {
+		juego.sumarPuntaje(puntosSalida)
+		juego.superarNivel()
+	}
+}
+
+// Las pelotas pegadas se lanzan cuando el jugador lanza o cuando se les termina el tiempo
+func (juego *Game) actualizarPegadas() {
+	for i := range juego.jugador.pelotas {
+		bola := &juego.jugador.pelotas[i]
+		if !bola.pegada {
+			continue
+		}
+		bola.tiempoPegada -= juego.dt
+		if juego.jugador.entrada.lanzar || bola.tiempoPegada <= 0 {
+			bola.lanzarDesdeBarra()
+		}
+	}
+}
+
+// Cancelamos todos los poderes y las capsulas que estaban cayendo (al perder una vida o cambiar de nivel)
+func (juego *Game) cancelarPoderes() {
+	for p := poder(0); p < cantidadPoderes; p++ {
+		juego.desactivarPoder(p)
+	}
+	juego.capsulas = nil
+	juego.disparos = nil
+}
+
+// Cada pelota en juego se divide en tres (la original y dos desviadas), sin pasar de maxPelotas
+func (juego *Game) dividirPelotas() {
+	jugador := &juego.jugador
+	originales := len(jugador.pelotas)
+
+	for i := 0; i < originales; i++ {
+		for _, desvio := range []float32{-desvioDividir, desvioDividir} {
+			if len(jugador.pelotas) >= maxPelotas {
+				return
+			}
+			nueva := jugador.pelotas[i]
+			if nueva.pegada {
+				continue
+			}
+			nueva.vel_x += desvio
+			jugador.pelotas = append(jugador.pelotas, nueva)
+		}
+	}
+}
+
+// Lanzamos las pelotas pegadas a la barra
+func (juego *Game) soltarPelotas() {
+	for i := range juego.jugador.pelotas {
+		if juego.jugador.pelotas[i].pegada {
+			juego.jugador.pelotas[i].lanzarDesdeBarra()
+		}
+	}
+}
+
+// Metodo que pega la pelota a la barra en el lugar donde la toco
+func (bola *pelota) pegar() {
+	bola.pegada = true
+	bola.desvioPegada = bola.pos.x - bola.jugador.pos.x
+	bola.tiempoPegada = segundosPegada
+	bola.seguirBarra()
+}
+
+// Apoyamos la primera pelota sobre la barra para el lanzamiento (no se suelta sola, espera que el jugador lance)
+func (juego *Game) apoyarPelota() {
+	bola := &juego.jugador.pelotas[0]
+	bola.pegada = true
+	bola.tiempoPegada = 0
+	juego.apuntar()
+}
+
+// Antes del lanzamiento la pelota recorre ida y vuelta la mitad central de la barra: el segmento
+// en el que este al lanzar decide el angulo de salida (configuracion_velocidad)
+func (juego *Game) apuntar() {
+	jugador := &juego.jugador
+	recorrido := float32(jugador.ancho) / 4
+
+	fase := float32(juego.tick) * juego.dt / periodoApunte
+	fase -= float32(int(fase))
+	onda := 4*abs32(fase-0.5) - 1 // Triangular entre -1 y 1
+
+	for i := range jugador.pelotas {
+		if jugador.pelotas[i].pegada {
+			jugador.pelotas[i].desvioPegada = recorrido * onda
+			jugador.pelotas[i].seguirBarra()
+		}
+	}
+}
+
+// Metodo que mantiene la pelota pegada apoyada sobre la barra
+func (bola *pelota) seguirBarra() {
+	jugador := bola.jugador
+	bola.pos.x = jugador.pos.x + bola.desvioPegada
+	bola.pos.y = jugador.pos.y - float32(jugador.alto)/2 - bola.radio
+}
+
+// Metodo que despega la pelota y la lanza con el angulo del segmento de la barra donde esta apoyada
+func (bola *pelota) lanzarDesdeBarra() {
+	bola.pegada = false
+	bola.vel_y = abs32(bola.vel_y)
+	if bola.vel_y == 0 {
+		bola.vel_y = 10
+	}
+	configuracion_velocidad(bola, bola.jugador, velocidades_x)
+}
+
+// Letras de las capsulas (5x7 como los numeros de graficarPuntaje)
+var simbolosLetras = map[byte][]byte{
+	'E': {
+		1, 1, 1, 1, 1,
+		1, 0, 0, 0, 0,
+		1, 0, 0, 0, 0,
+		1, 1, 1, 1, 0,
+		1, 0, 0, 0, 0,
+		1, 0, 0, 0, 0,
+		1, 1, 1, 1, 1,
+	},
+	'S': {
+		0, 1, 1, 1, 1,
+		1, 0, 0, 0, 0,
+		1, 0, 0, 0, 0,
+		0, 1, 1, 1, 0,
+		0, 0, 0, 0, 1,
+		0, 0, 0, 0, 1,
+		1, 1, 1, 1, 0,
+	},
+	'C': {
+		0, 1, 1, 1, 0,
+		1, 0, 0, 0, 1,
+		1, 0, 0, 0, 0,
+		1, 0, 0, 0, 0,
+		1, 0, 0, 0, 0,
+		1, 0, 0, 0, 1,
+		0, 1, 1, 1, 0,
+	},
+	'L': {
+		1, 0, 0, 0, 0,
+		1, 0, 0, 0, 0,
+		1, 0, 0, 0, 0,
+		1, 0, 0, 0, 0,
+		1, 0, 0, 0, 0,
+		1, 0, 0, 0, 0,
+		1, 1, 1, 1, 1,
+	},
+	'D': {
+		1, 1, 1, 1, 0,
+		1, 0, 0, 0, 1,
+		1, 0, 0, 0, 1,
+		1, 0, 0, 0, 1,
+		1, 0, 0, 0, 1,
+		1, 0, 0, 0, 1,
+		1, 1, 1, 1, 0,
+	},
+	'B': {
+		1, 1, 1, 1, 0,
+		1, 0, 0, 0, 1,
+		1, 0, 0, 0, 1,
+		1, 1, 1, 1, 0,
+		1, 0, 0, 0, 1,
+		1, 0, 0, 0, 1,
+		1, 1, 1, 1, 0,
+	},
+	'P': {
+		1, 1, 1, 1, 0,
+		1, 0, 0, 0, 1,
+		1, 0, 0, 0, 1,
+		1, 1, 1, 1, 0,
+		1, 0, 0, 0, 0,
+		1, 0, 0, 0, 0,
+		1, 0, 0, 0, 0,
+	},
+}
+
+// Grafica un rectangulo del color del poder con su letra en blanco en el centro
+func graficarIconoPoder(p poder, centro pos, ancho, alto int, ventana []byte) {
+	startX := centro.x - float32(ancho)/2
+	startY := centro.y - float32(alto)/2
+
+	for y := 0; y < alto; y++ {
+		for x := 0; x < ancho; x++ {
+			colorear(pos{startX + float32(x), startY + float32(y)}, poderes[p].color, ventana)
+		}
+	}
+
+	letraX := centro.x - 5.0/2
+	letraY := centro.y - 7.0/2
+	for index, value := range simbolosLetras[poderes[p].letra] {
+		if value == 1 {
+			colorear(pos{letraX + float32(index%5), letraY + float32(index/5)}, color{255, 255, 255, 255}, ventana)
+		}
+	}
+}
+
+// Poderes activos que se muestran abajo (los que tienen duracion), en orden
+func poderesMostrados(efectos efectos) []poder {
+	var mostrados []poder
+	for p := poder(0); p < cantidadPoderes; p++ {
+		if efectos.activo(p) && poderes[p].duracion != 0 {
+			mostrados = append(mostrados, p)
+		}
+	}
+	return mostrados
+}
+
+// Centro del icono del poder activo numero 'i'
+func centroPoderActivo(i int) pos {
+	return pos{float32(anchoVentana)/2 - 100 + float32(i*(anchoCapsula+10)), float32(altoVentana) - 24}
+}
+
+// Largo en pixeles de la barra del tiempo que le queda al poder
+func largoTiempoPoder(efectos efectos, p poder) int {
+	return int(float32(anchoCapsula) * efectos.restante[p] / poderes[p].duracion)
+}
+
+// Grafica el icono del poder con la barra de tiempo restante debajo
+func graficarPoderActivo(p poder, centro pos, largo int, ventana []byte) {
+	graficarIconoPoder(p, centro, anchoCapsula, altoCapsula, ventana)
+
+	for i := 0; i < largo; i++ {
+		for j := 0; j < 2; j++ {
+			colorear(pos{centro.x - float32(anchoCapsula)/2 + float32(i), centro.y + float32(altoCapsula)/2 + 2 + float32(j)}, poderes[p].color, ventana)
+		}
+	}
+}
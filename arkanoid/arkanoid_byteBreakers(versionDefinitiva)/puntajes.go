@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ------------------------------------------------------------------------------------
+// ----------------------------------PUNTAJES------------------------------------------
+// ------------------------------------------------------------------------------------
+
+// Cantidad de puntajes que guarda la tabla
+const maxPuntajes = 10
+
+// Largo maximo del nombre del jugador en la tabla
+const maxNombre = 12
+
+// Puntaje de una partida terminada
+type puntaje struct {
+	nombre   string
+	score    int
+	nivel    int   // Nivel alcanzado (desde 1)
+	porNivel []int // Puntos conseguidos en cada nivel jugado
+}
+
+// Tabla de mejores puntajes ordenada de mayor a menor
+type tablaPuntajes []puntaje
+
+func rutaPuntajes() (string, error) {
+	directorio, err := directorioJuego()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(directorio, "puntajes.txt"), nil
+}
+
+// Leemos la tabla guardada. Una linea por puntaje: "score<TAB>nivel<TAB>puntos,por,nivel<TAB>nombre".
+// Las lineas rotas se ignoran y si el archivo no se puede leer la tabla arranca vacia
+func leerPuntajes() tablaPuntajes {
+	var tabla tablaPuntajes
+
+	ruta, err := rutaPuntajes()
+	if err != nil {
+		return tabla
+	}
+	datos, err := os.ReadFile(ruta)
+	if err != nil {
+		return tabla
+	}
+
+	for _, linea := range strings.Split(string(datos), "\n") {
+		if p, ok := leerLineaPuntaje(linea); ok {
+			tabla = tabla.agregar(p)
+		}
+	}
+
+	return tabla
+}
+
+func leerLineaPuntaje(linea string) (puntaje, bool) {
+	campos := strings.SplitN(strings.TrimRight(linea, "\r"), "\t", 4)
+	if len(campos) != 4 {
+		return puntaje{}, false
+	}
+
+	score, err := strconv.Atoi(campos[0])
+	if err != nil || score < 0 {
+		return puntaje{}, false
+	}
+	nivel, err := strconv.Atoi(campos[1])
+	if err != nil || nivel < 1 {
+		return puntaje{}, false
+	}
+
+	var porNivel []int
+	if campos[2] != "" {
+		for _, texto := range strings.Split(campos[2], ",") {
+			puntos, err := strconv.Atoi(texto)
+			if err != nil || puntos < 0 {
+				return puntaje{}, false
+			}
+			porNivel = append(porNivel, puntos)
+		}
+	}
+
+	nombre := limpiarNombre(campos[3])
+	if nombre == "" {
+		return puntaje{}, false
+	}
+
+	return puntaje{nombre, score, nivel, porNivel}, true
+}
+
+// Guardamos la tabla completa de forma atomica
+func guardarPuntajes(tabla tablaPuntajes) error {
+	ruta, err := rutaPuntajes()
+	if err != nil {
+		return err
+	}
+
+	var datos strings.Builder
+	for _, p := range tabla {
+		porNivel := make([]string, len(p.porNivel))
+		for i, puntos := range p.porNivel {
+			porNivel[i] = strconv.Itoa(puntos)
+		}
+		fmt.Fprintf(&datos, "%d\t%d\t%s\t%s\n", p.score, p.nivel, strings.Join(porNivel, ","), p.nombre)
+	}
+
+	return escribirArchivoAtomico(ruta, []byte(datos.String()))
+}
+
+// Metodo que indica si el score entra en la tabla
+func (tabla tablaPuntajes) entra(score int) bool {
+	return score > 0 && (len(tabla) < maxPuntajes || score > tabla[len(tabla)-1].score)
+}
+
+// Metodo que devuelve la tabla con el puntaje agregado en su lugar, recortada a maxPuntajes
+func (tabla tablaPuntajes) agregar(nuevo puntaje) tablaPuntajes {
+	tabla = append(tabla, nuevo)
+	// A igual score queda primero el mas viejo
+	sort.SliceStable(tabla, func(i, j int) bool { return tabla[i].score > tabla[j].score })
+	if len(tabla) > maxPuntajes {
+		tabla = tabla[:maxPuntajes]
+	}
+	return tabla
+}
+
+// Texto del detalle de puntos por nivel, por ejemplo "L1 500 / L2 730"
+func (p puntaje) detalle() string {
+	partes := make([]string, len(p.porNivel))
+	for i, puntos := range p.porNivel {
+		partes[i] = fmt.Sprintf("L%d %d", i+1, puntos)
+	}
+	return strings.Join(partes, " / ")
+}
+
+// Sacamos del nombre los caracteres que rompen el archivo y lo recortamos a maxNombre letras
+func limpiarNombre(nombre string) string {
+	var limpio strings.Builder
+	letras := 0
+	for _, letra := range nombre {
+		if letra < ' ' || letra == utf8.RuneError || letras == maxNombre {
+			continue
+		}
+		limpio.WriteRune(letra)
+		letras++
+	}
+	return strings.TrimSpace(limpio.String())
+}
+
+// Nombre que el jugador escribe al entrar en la tabla
+type entradaNombre struct {
+	texto string
+}
+
+// Metodo que agrega el texto tipeado respetando el largo maximo
+func (entrada *entradaNombre) escribir(texto string) {
+	for _, letra := range texto {
+		if letra < ' ' || letra == utf8.RuneError || utf8.RuneCountInString(entrada.texto) >= maxNombre {
+			continue
+		}
+		entrada.texto += string(letra)
+	}
+}
+
+// Metodo que borra la ultima letra
+func (entrada *entradaNombre) borrar() {
+	if entrada.texto == "" {
+		return
+	}
+	_, largo := utf8.DecodeLastRuneInString(entrada.texto)
+	entrada.texto = entrada.texto[:len(entrada.texto)-largo]
+}
+
+// Metodo que devuelve el nombre final (si quedo vacio usamos uno generico)
+func (entrada *entradaNombre) nombre() string {
+	if nombre := limpiarNombre(entrada.texto); nombre != "" {
+		return nombre
+	}
+	return "PLAYER"
+}
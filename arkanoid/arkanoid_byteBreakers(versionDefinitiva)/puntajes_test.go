@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// Usamos una carpeta temporal como carpeta de configuracion, asi la prueba no toca la tabla real
+func directorioPrueba(t *testing.T) string {
+	t.Helper()
+	carpeta := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", carpeta)
+	t.Setenv("HOME", carpeta)
+	t.Setenv("AppData", carpeta)
+
+	ruta, err := rutaPuntajes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ruta
+}
+
+// Las lineas rotas se saltean sin perder las buenas que estan antes y despues
+func TestLeerPuntajesArchivoRoto(t *testing.T) {
+	ruta := directorioPrueba(t)
+	lineas := "300\t2\t100,200\tANA\n" +
+		"basura sin tabs\n" +
+		"500\t1\n" +
+		"-5\t1\t\tNEGATIVO\n" +
+		"abc\t1\t\tLETRAS\n" +
+		"400\t0\t\tNIVEL CERO\n" +
+		"400\t1\t10,x\tDETALLE ROTO\n" +
+		"400\t1\t\t   \n" +
+		"\x00\xff\xfe\t\t\t\n" +
+		"\n" +
+		"900\t3\t300,300,300\tBETO\r\n" +
+		"100\t1\t\tCARLA"
+
+	if err := os.WriteFile(ruta, []byte(lineas), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	esperada := tablaPuntajes{
+		{"BETO", 900, 3, []int{300, 300, 300}},
+		{"ANA", 300, 2, []int{100, 200}},
+		{"CARLA", 100, 1, nil},
+	}
+
+	if tabla := leerPuntajes(); !reflect.DeepEqual(tabla, esperada) {
+		t.Errorf("tabla leida %+v, se esperaba %+v", tabla, esperada)
+	}
+}
+
+// Sin archivo la tabla arranca vacia, y lo que se guarda se vuelve a leer igual
+func TestGuardarYLeerPuntajes(t *testing.T) {
+	directorioPrueba(t)
+	if tabla := leerPuntajes(); len(tabla) != 0 {
+		t.Fatalf("sin archivo la tabla tiene %d puntajes", len(tabla))
+	}
+
+	tabla := tablaPuntajes{}.agregar(puntaje{"ANA", 300, 2, []int{100, 200}}).agregar(puntaje{"BETO", 50, 1, nil})
+	if err := guardarPuntajes(tabla); err != nil {
+		t.Fatal(err)
+	}
+	if leida := leerPuntajes(); !reflect.DeepEqual(leida, tabla) {
+		t.Errorf("tabla leida %+v, se esperaba %+v", leida, tabla)
+	}
+}
+
+func TestAgregarPuntajes(t *testing.T) {
+	var tabla tablaPuntajes
+	for i, score := range []int{50, 300, 120, 300, 10, 80, 700, 20, 90, 300, 60, 5} {
+		tabla = tabla.agregar(puntaje{nombre: string(rune('A' + i)), score: score, nivel: 1})
+	}
+
+	if len(tabla) != maxPuntajes {
+		t.Fatalf("la tabla tiene %d puntajes, se esperaban %d", len(tabla), maxPuntajes)
+	}
+
+	// De mayor a menor; a igual score primero el que entro antes. Se caen los dos mas bajos (10 y 5)
+	var nombres []string
+	for _, p := range tabla {
+		nombres = append(nombres, p.nombre)
+	}
+	esperados := []string{"G", "B", "D", "J", "C", "I", "F", "K", "A", "H"}
+	if !reflect.DeepEqual(nombres, esperados) {
+		t.Errorf("orden %v, se esperaba %v", nombres, esperados)
+	}
+
+	if tabla.entra(20) || !tabla.entra(21) || tabla.entra(0) {
+		t.Errorf("con la tabla llena solo entra un score mayor a %d", tabla[len(tabla)-1].score)
+	}
+}
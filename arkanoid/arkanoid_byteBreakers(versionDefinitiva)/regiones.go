@@ -0,0 +1,180 @@
+package main
+
+import "math"
+
+// ------------------------------------------------------------------------------------
+// ---------------------------------REGIONES-------------------------------------------
+// ------------------------------------------------------------------------------------
+
+// Rectangulo de pixeles de la ventana: de (x0, y0) incluido a (x1, y1) sin incluir
+type rectangulo struct {
+	x0, y0, x1, y1 int
+}
+
+var ventanaEntera = rectangulo{0, 0, anchoVentana, altoVentana}
+
+// Rectangulo con todos los pixeles que puede pintar algo dibujado desde (x, y) con ese ancho y alto. colorear
+// trunca las coordenadas, asi que tomamos un pixel de mas a la derecha y abajo. Se recorta a la ventana
+func rectDesde(x, y, ancho, alto float32) rectangulo {
+	r := rectangulo{
+		x0: int(math.Floor(float64(x))),
+		y0: int(math.Floor(float64(y))),
+		x1: int(math.Floor(float64(x+ancho))) + 1,
+		y1: int(math.Floor(float64(y+alto))) + 1,
+	}
+	return r.recortar(ventanaEntera)
+}
+
+// Rectangulo de algo centrado en 'centro'
+func rectCentrado(centro pos, ancho, alto float32) rectangulo {
+	return rectDesde(centro.x-ancho/2, centro.y-alto/2, ancho, alto)
+}
+
+// Metodo que indica si el rectangulo no tiene pixeles
+func (r rectangulo) vacio() bool {
+	return r.x0 >= r.x1 || r.y0 >= r.y1
+}
+
+// Metodo que indica si los rectangulos comparten algun pixel
+func (r rectangulo) toca(otro rectangulo) bool {
+	return !r.vacio() && !otro.vacio() && r.x0 < otro.x1 && otro.x0 < r.x1 && r.y0 < otro.y1 && otro.y0 < r.y1
+}
+
+// Metodo que indica si el otro rectangulo esta entero dentro de este
+func (r rectangulo) contiene(otro rectangulo) bool {
+	return otro.vacio() || r.x0 <= otro.x0 && r.y0 <= otro.y0 && otro.x1 <= r.x1 && otro.y1 <= r.y1
+}
+
+// Metodo que devuelve el menor rectangulo que cubre a los dos
+func (r rectangulo) union(otro rectangulo) rectangulo {
+	if r.vacio() {
+		return otro
+	}
+	if otro.vacio() {
+		return r
+	}
+	return rectangulo{minInt(r.x0, otro.x0), minInt(r.y0, otro.y0), maxInt(r.x1, otro.x1), maxInt(r.y1, otro.y1)}
+}
+
+// Metodo que devuelve la parte del rectangulo que cae dentro del otro
+func (r rectangulo) recortar(otro rectangulo) rectangulo {
+	return rectangulo{maxInt(r.x0, otro.x0), maxInt(r.y0, otro.y0), minInt(r.x1, otro.x1), minInt(r.y1, otro.y1)}
+}
+
+// Metodo que devuelve el area en pixeles
+func (r rectangulo) area() int {
+	if r.vacio() {
+		return 0
+	}
+	return (r.x1 - r.x0) * (r.y1 - r.y0)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// ------------------------------------------------------------------------------------
+// ---------------------------------REDIBUJO-------------------------------------------
+// ------------------------------------------------------------------------------------
+
+// Lo que distingue a un dibujo de un fotograma a otro
+type firma struct {
+	rect    rectangulo
+	aspecto aspecto
+}
+
+// Redibuja en la ventana solo lo que cambio desde el fotograma anterior. La ventana tiene que ser siempre la misma
+// y nadie mas tiene que dibujar en ella (si no, hay que invalidar para que el proximo fotograma sea completo)
+type redibujador struct {
+	anteriores map[firma]int // Dibujos del fotograma anterior (puede haber dos iguales)
+	velo       color
+	valido     bool // La ventana tiene el fotograma anterior
+}
+
+// Metodo que hace que el proximo fotograma se dibuje entero
+func (r *redibujador) invalidar() {
+	r.valido = false
+}
+
+// Metodo que dibuja el fotograma y devuelve los rectangulos que cambiaron, que no se superponen entre si
+func (r *redibujador) dibujar(juego *Game, alpha float32, ventana []byte) []rectangulo {
+	dibujos := dibujosPartida(juego, alpha)
+	velo := veloPartida(juego)
+
+	actuales := make(map[firma]int, len(dibujos))
+	for _, d := range dibujos {
+		actuales[firma{d.rect, d.aspecto}]++
+	}
+
+	// Lo que estaba y ya no esta (hay que borrarlo) y lo que no estaba (hay que dibujarlo)
+	var sucios []rectangulo
+	if !r.valido || velo != r.velo {
+		sucios = []rectangulo{ventanaEntera}
+	} else {
+		for f, cantidad := range r.anteriores {
+			if actuales[f] != cantidad {
+				sucios = append(sucios, f.rect)
+			}
+		}
+		for f, cantidad := range actuales {
+			if r.anteriores[f] != cantidad {
+				sucios = append(sucios, f.rect)
+			}
+		}
+		sucios = ampliarSucios(sucios, dibujos)
+	}
+
+	for _, rect := range sucios {
+		dibujarRegion(rect, dibujos, velo, ventana)
+	}
+
+	r.anteriores, r.velo, r.valido = actuales, velo, true
+	return sucios
+}
+
+// Agrandamos los rectangulos hasta que cada dibujo que toca alguno quede entero adentro y juntamos los que se
+// superponen, asi cada dibujo se redibuja una sola vez y sin pintar fuera de los rectangulos
+func ampliarSucios(sucios []rectangulo, dibujos []dibujo) []rectangulo {
+	for cambio := true; cambio; {
+		cambio = false
+
+		for i := range sucios {
+			for _, d := range dibujos {
+				if d.rect.toca(sucios[i]) && !sucios[i].contiene(d.rect) {
+					sucios[i] = sucios[i].union(d.rect)
+					cambio = true
+				}
+			}
+		}
+
+		juntos := sucios[:0]
+		for _, rect := range sucios {
+			if rect.vacio() {
+				continue
+			}
+			unido := false
+			for i := range juntos {
+				if juntos[i].toca(rect) {
+					juntos[i] = juntos[i].union(rect)
+					unido, cambio = true, true
+					break
+				}
+			}
+			if !unido {
+				juntos = append(juntos, rect)
+			}
+		}
+		sucios = juntos
+	}
+	return sucios
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Dibujamos cada tick entero en una ventana y solo lo que cambio en otra, y verificamos que queden iguales.
+// Cada tanto pausamos la partida para probar tambien el velo, y usamos distintos alpha para que el jugador y
+// las pelotas caigan entre pixeles. 'antes' se llama antes de cada tick para provocar lo que se quiera probar
+func verificarRedibujo(t *testing.T, juego *Game, ticks int, antes func(tick int)) {
+	t.Helper()
+	completa, parcial := nuevaVentana(), nuevaVentana()
+	var redibujo redibujador
+
+	for tick := 0; tick < ticks && juego.estado != win && juego.estado != loose; tick++ {
+		switch tick % 500 {
+		case 200:
+			juego.pausar()
+		case 210:
+			juego.reanudar()
+		}
+		if antes != nil {
+			antes(tick)
+		}
+		juego.Step()
+
+		alpha := float32(tick%4) / 4
+		dibujarPartida(juego, alpha, completa)
+		redibujo.dibujar(juego, alpha, parcial)
+		if !bytes.Equal(completa, parcial) {
+			distintos := 0
+			for i := 0; i < len(completa); i += 4 {
+				if !bytes.Equal(completa[i:i+4], parcial[i:i+4]) {
+					distintos++
+				}
+			}
+			t.Fatalf("tick %d: %d pixeles distintos al dibujo completo", juego.tick, distintos)
+		}
+	}
+}
+
+// Unos cientos de ticks del bot en los que se rompen ladrillos, cae una capsula y se pierde la pelota
+func TestRedibujo(t *testing.T) {
+	juego := juegoPrueba(t, 1)
+	restantes, vidas := juego.restantes, juego.jugador.vida
+	huboCapsula := false
+
+	verificarRedibujo(t, juego, 400, func(tick int) {
+		switch tick {
+		case 100:
+			juego.capsulas = append(juego.capsulas, capsula{pos: pos{200, 300}, ancho: anchoCapsula, alto: altoCapsula, vel_y: velocidadCapsula, poder: poderLaser})
+		case 300:
+			// Mandamos todas las pelotas por debajo de la barra
+			for i := range juego.jugador.pelotas {
+				juego.jugador.pelotas[i].pos.y = altoVentana - 1
+				juego.jugador.pelotas[i].vel_y = 10
+			}
+		}
+		huboCapsula = huboCapsula || len(juego.capsulas) > 0
+	})
+
+	if juego.restantes == restantes || !huboCapsula || juego.jugador.vida == vidas {
+		t.Errorf("la partida no paso por todo lo que se queria probar: ladrillos %d de %d, capsula %v, vidas %d de %d",
+			juego.restantes, restantes, huboCapsula, juego.jugador.vida, vidas)
+	}
+}
+
+// La misma verificacion durante miles de ticks (se saltea con -short)
+func TestRedibujoLargo(t *testing.T) {
+	if testing.Short() {
+		t.Skip("partida larga")
+	}
+	verificarRedibujo(t, juegoPrueba(t, 1), 6000, nil)
+}
+
+// Tiempo por fotograma de dibujar la ventana entera y de redibujar solo lo que cambio, en una partida jugada por el
+// bot. El redibujo informa ademas que porcentaje de la ventana tuvo que volver a pintar
+func BenchmarkDibujo(b *testing.B) {
+	b.Run("completo", func(b *testing.B) {
+		benchmarkDibujo(b, func(juego *Game, ventana []byte) {
+			dibujarPartida(juego, 1, ventana)
+		})
+	})
+
+	b.Run("redibujo", func(b *testing.B) {
+		var redibujo redibujador
+		pixeles := 0
+		benchmarkDibujo(b, func(juego *Game, ventana []byte) {
+			for _, rect := range redibujo.dibujar(juego, 1, ventana) {
+				pixeles += rect.area()
+			}
+		})
+		b.ReportMetric(100*float64(pixeles)/float64(b.N*ventanaEntera.area()), "%redibujado")
+	})
+}
+
+// Un fotograma por tick de una partida del bot; si termina empieza otra. Solo se mide el dibujo, no el tick
+func benchmarkDibujo(b *testing.B, dibujar func(juego *Game, ventana []byte)) {
+	juego := juegoPrueba(b, 1)
+	ventana := nuevaVentana()
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		if juego.estado == win || juego.estado == loose {
+			juego = juegoPrueba(b, int64(n))
+		}
+		juego.Step()
+		b.StartTimer()
+
+		dibujar(juego, ventana)
+	}
+}
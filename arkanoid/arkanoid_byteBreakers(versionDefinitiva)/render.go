@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// ------------------------------------------------------------------------------------
+// ----------------------------------RENDER--------------------------------------------
+// ------------------------------------------------------------------------------------
+
+// Ventana donde dibujamos: anchoVentana x altoVentana pixeles de 4 bytes, como la textura de SDL
+func nuevaVentana() []byte {
+	return make([]byte, anchoVentana*altoVentana*4)
+}
+
+// Capas de la ventana, de atras hacia adelante. Lo de cada capa se dibuja despues de lo de la anterior, asi lo que
+// tiene alfa se mezcla con todo lo de las capas de atras
+type capa int
+
+const (
+	capaFondo     capa = iota
+	capaLadrillos      // Muro del nivel
+	capaEntidades      // Pelotas, barra, capsulas, disparos, enemigos, jefe y sus proyectiles
+	capaMarcador       // Vidas, puntaje, poderes activos y vida del jefe
+	capaVelo           // Velo semitransparente sobre la partida congelada (pausa, nivel superado o fin de la partida)
+	cantidadCapas
+)
+
+// Velos que oscurecen la partida detras de los textos: la pausa deja ver mas el nivel que el fin de la partida
+var (
+	veloPausa = color{0, 0, 0, 150}
+	veloFin   = color{0, 0, 0, 200}
+)
+
+// Como se dibuja el puntaje en el marcador: tamaño de cada pixel de los digitos, color y centro del primer digito
+type estiloPuntaje struct {
+	escala int
+	color  color
+	centro func(score int) pos
+}
+
+// El del marcador de la partida, abajo a la derecha
+var puntajeMarcador = estiloPuntaje{3, color{255, 255, 255, 255}, centroPuntaje}
+
+// Algo que se dibuja en la ventana. rect tiene todos los pixeles que puede pintar y aspecto lo que cambia como
+// se ve: si de un fotograma al siguiente no cambia ninguno de los dos no hace falta volver a dibujarlo
+type dibujo struct {
+	rect    rectangulo
+	aspecto aspecto
+	dibujar func(ventana []byte)
+}
+
+// Lo que define como se ve un dibujo ademas de su rectangulo
+type aspecto struct {
+	elemento string // Que es ("ladrillo", "pelota"...), para no confundir dos cosas distintas en el mismo lugar
+	pos      pos
+	color    color
+	valor    int // Vidas, puntaje, largo de una barra...
+}
+
+// Dibujamos la partida entera en la ventana, sin SDL. Se usa igual con ventana y sin ventana, asi lo que se
+// exporta a PNG es lo mismo que se ve (sin los textos, que los pone SDL arriba con la fuente).
+// alpha es la fraccion del tick siguiente ya transcurrida, para interpolar el jugador y las pelotas
+func dibujarPartida(juego *Game, alpha float32, ventana []byte) {
+	dibujarRegion(ventanaEntera, dibujosPartida(juego, alpha), veloPartida(juego), ventana)
+}
+
+// Todo lo que se dibuja de la partida, capa por capa de atras hacia adelante
+func dibujosPartida(juego *Game, alpha float32) []dibujo {
+	// Dibujamos el jugador y las pelotas entre el tick anterior y el actual
+	jugadorDibujo := interpolarJugador(juego.jugador, alpha)
+
+	var dibujos []dibujo
+	for c := capaLadrillos; c <= capaMarcador; c++ {
+		dibujos = agregarCapa(dibujos, c, juego, &jugadorDibujo)
+	}
+	return dibujos
+}
+
+// Agrega a la lista lo que le toca a la capa. El fondo y el velo no estan: pintan todo el rectangulo que se redibuja
+func agregarCapa(dibujos []dibujo, c capa, juego *Game, jugador *barra) []dibujo {
+	agregar := func(elemento string, rect rectangulo, p pos, col color, valor int, dibujar func(ventana []byte)) {
+		dibujos = append(dibujos, dibujo{rect: rect, aspecto: aspecto{elemento, p, col, valor}, dibujar: dibujar})
+	}
+
+	switch c {
+	case capaLadrillos:
+		for i := range juego.muro {
+			bloque := &juego.muro[i]
+			// Los ladrillos rotos son transparentes: no pintan nada
+			if bloque.color.a == 0 {
+				continue
+			}
+			agregar("ladrillo", rectCentrado(bloque.pos, float32(bloque.ancho), float32(bloque.alto)), bloque.pos, bloque.color, 0,
+				func(ventana []byte) { llamarDibujar(bloque, ventana) })
+		}
+
+	case capaEntidades:
+		for i := range jugador.pelotas {
+			bola := &jugador.pelotas[i]
+			agregar("pelota", rectCentrado(bola.pos, 2*bola.radio, 2*bola.radio), bola.pos, bola.color, 0,
+				func(ventana []byte) { llamarDibujar(bola, ventana) })
+		}
+		agregar("barra", rectCentrado(jugador.pos, float32(jugador.ancho), float32(jugador.alto)), jugador.pos, jugador.color, 0,
+			func(ventana []byte) { llamarDibujar(jugador, ventana) })
+		for i := range juego.capsulas {
+			capsula := &juego.capsulas[i]
+			agregar("capsula", rectCentrado(capsula.pos, float32(capsula.ancho), float32(capsula.alto)), capsula.pos, poderes[capsula.poder].color, int(capsula.poder),
+				func(ventana []byte) { llamarDibujar(capsula, ventana) })
+		}
+		for i := range juego.disparos {
+			disparo := &juego.disparos[i]
+			agregar("disparo", rectCentrado(disparo.pos, float32(disparo.ancho), float32(disparo.alto)), disparo.pos, disparo.color, 0,
+				func(ventana []byte) { llamarDibujar(disparo, ventana) })
+		}
+		for indice, x := range puertasEnemigos {
+			x, colorPuerta := x, juego.colorPuerta(indice)
+			agregar("puerta", rectDesde(x-anchoPuerta/2, 0, anchoPuerta, altoPuerta), pos{x, 0}, colorPuerta, 0,
+				func(ventana []byte) { graficarPuerta(x, colorPuerta, ventana) })
+		}
+		for i := range juego.enemigos {
+			enemigo := &juego.enemigos[i]
+			agregar("enemigo", rectCentrado(enemigo.pos, 2*enemigo.radio, 2*enemigo.radio), enemigo.pos, enemigo.color, 0,
+				func(ventana []byte) { llamarDibujar(enemigo, ventana) })
+		}
+		if jefe := juego.jefe; jefe != nil && jefe.vida > 0 {
+			agregar("jefe", rectCentrado(jefe.pos, float32(jefe.ancho), float32(jefe.alto)), jefe.pos, jefe.colorDibujo(), 0,
+				func(ventana []byte) { llamarDibujar(jefe, ventana) })
+		}
+		if juego.jefe != nil {
+			for i := range juego.proyectiles {
+				proyectil := &juego.proyectiles[i]
+				agregar("proyectil", rectCentrado(proyectil.pos, 2*proyectil.radio, 2*proyectil.radio), proyectil.pos, proyectil.color, 0,
+					func(ventana []byte) { llamarDibujar(proyectil, ventana) })
+			}
+		}
+
+	case capaMarcador:
+		// Cada corazon ocupa 21 pixeles y estan separados cada 25 desde x = 10
+		agregar("vidas", rectDesde(10, altoVentana-30, float32(25*jugador.vida), 21), pos{}, color{}, jugador.vida,
+			func(ventana []byte) { graficarVida(*jugador, ventana) })
+		estilo := puntajeMarcador
+		centro, escala := estilo.centro(jugador.score), estilo.escala
+		digitos := len(strconv.Itoa(jugador.score))
+		agregar("puntaje", rectDesde(centro.x-float32(5*escala)/2, centro.y-float32(7*escala)/2, float32(digitos*6*escala), float32(7*escala)), centro, estilo.color, jugador.score,
+			func(ventana []byte) { graficarPuntaje(*jugador, ventana, escala, escala, centro, estilo.color) })
+		for i, p := range poderesMostrados(juego.efectos) {
+			p, centro, largo := p, centroPoderActivo(i), largoTiempoPoder(juego.efectos, p)
+			// El icono y debajo la barra de tiempo (2 pixeles de separacion y 2 de alto)
+			agregar("poder", rectDesde(centro.x-anchoCapsula/2, centro.y-altoCapsula/2, anchoCapsula, altoCapsula+4), centro, poderes[p].color, largo,
+				func(ventana []byte) { graficarPoderActivo(p, centro, largo, ventana) })
+		}
+		if jefe := juego.jefe; jefe != nil {
+			agregar("vida jefe", rectDesde(margenBarraVidaJefe, 12, largoBarraVidaJefe, altoBarraVidaJefe), pos{}, jefe.color, jefe.largoVida(),
+				func(ventana []byte) { graficarVidaJefe(jefe, ventana) })
+		}
+	}
+	return dibujos
+}
+
+// Velo que le toca a la partida segun su estado (transparente si no lleva)
+func veloPartida(juego *Game) color {
+	switch juego.estado {
+	case pausa:
+		return veloPausa
+	case cleared, win, loose:
+		return veloFin
+	}
+	return color{}
+}
+
+// Redibujamos el rectangulo: el fondo, los dibujos que lo tocan y el velo encima. Cada dibujo que lo toca tiene que
+// estar entero adentro (se dibuja completo), si no se pintaria por fuera del rectangulo
+func dibujarRegion(r rectangulo, dibujos []dibujo, velo color, ventana []byte) {
+	pintarRect(r, color{0, 0, 0, 255}, ventana)
+	for _, d := range dibujos {
+		if d.rect.toca(r) {
+			d.dibujar(ventana)
+		}
+	}
+	if velo.a > 0 {
+		pintarRect(r, velo, ventana)
+	}
+}
+
+// Pintamos el color sobre el rectangulo (mezclandolo si no es opaco). Cada gorrutina se encarga de un grupo de filas
+func pintarRect(r rectangulo, c color, ventana []byte) {
+	numGorrutinas := minInt(runtime.NumCPU(), r.y1-r.y0)
+	if numGorrutinas <= 0 {
+		return
+	}
+	filas := (r.y1 - r.y0 + numGorrutinas - 1) / numGorrutinas
+
+	var wg sync.WaitGroup
+	wg.Add(numGorrutinas)
+	for i := 0; i < numGorrutinas; i++ {
+		go func(i int) {
+			defer wg.Done()
+			for y := r.y0 + i*filas; y < r.y0+(i+1)*filas && y < r.y1; y++ {
+				for x := r.x0; x < r.x1; x++ {
+					colorear(pos{float32(x), float32(y)}, c, ventana)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// Imagen con lo que muestra la ventana, leyendo cada pixel con el formato de la ventana. La textura de SDL
+// no mezcla con lo que hay detras, por eso el alfa de la imagen es siempre opaco
+func imagenVentana(ventana []byte) *image.RGBA {
+	imagen := image.NewRGBA(image.Rect(0, 0, anchoVentana, altoVentana))
+	for i := 0; i+3 < len(ventana); i += 4 {
+		c := formatoVentana.leer(ventana[i : i+4])
+		imagen.Pix[i], imagen.Pix[i+1], imagen.Pix[i+2], imagen.Pix[i+3] = c.r, c.g, c.b, 255
+	}
+	return imagen
+}
+
+// Guardamos la ventana como imagen PNG
+func guardarPNG(ruta string, ventana []byte) error {
+	return guardarImagen(ruta, imagenVentana(ventana))
+}
+
+// Guardamos una imagen como PNG
+func guardarImagen(ruta string, imagen image.Image) error {
+	archivo, err := os.Create(ruta)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(archivo, imagen); err != nil {
+		archivo.Close()
+		return err
+	}
+	return archivo.Close()
+}
+
+// Exporta uno de cada 'cada' ticks de la partida como PNG numerado por tick en la carpeta
+type exportadorPNG struct {
+	carpeta string
+	cada    int
+}
+
+// Metodo que exporta el fotograma si el tick actual le toca. Dibuja en 'ventana' (que se reusa entre llamadas)
+func (exportador *exportadorPNG) exportar(juego *Game, ventana []byte) error {
+	if exportador.cada <= 0 || juego.tick%exportador.cada != 0 {
+		return nil
+	}
+	dibujarPartida(juego, 1, ventana)
+	return guardarPNG(filepath.Join(exportador.carpeta, fmt.Sprintf("tick%06d.png", juego.tick)), ventana)
+}
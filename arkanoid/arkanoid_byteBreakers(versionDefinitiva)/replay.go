@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+)
+
+// ------------------------------------------------------------------------------------
+// ---------------------------------REPETICIONES---------------------------------------
+// ------------------------------------------------------------------------------------
+
+// Formato de las repeticiones (binario, enteros como varint):
+//
+//	"ARKR" version
+//	semilla frecuencia cantidadNiveles huellaNivel...       -> todo lo necesario para armar la misma partida
+//	nivelInicial                                               (las huellas son de 8 bytes, no varint)
+//	velocidadBarra vidas                                    -> configuracion del jugador con la que se grabo
+//	cantidadTramos (repeticiones teclas analogico)...       -> entradas de cada tick comprimidas por tramos iguales
+//	                                                           (analogico en octavos de pixel)
+//	score vida huellaMuro                                   -> resultado final esperado
+const firmaRepeticion = "ARKR"
+const versionRepeticion = 3
+
+// Ticks que puede tener una repeticion (unas 46 horas a 60 ticks por segundo). Un archivo roto o armado a mano
+// puede decir cualquier cantidad y sin tope la lectura crece hasta quedarse sin memoria
+const maxTicksRepeticion = 10_000_000
+
+// Niveles que puede tener la campaña de una repeticion
+const maxNivelesRepeticion = 1000
+
+// Partida grabada: con que se armo, la entrada de cada tick y como termino
+type grabacion struct {
+	semilla      int64
+	frecuencia   int
+	niveles      []uint64 // Huella de cada nivel de la campaña, en orden: no depende de donde estaban los archivos
+	nivelInicial int
+	velocidad    int // Velocidad de la barra y vidas iniciales de la configuracion del jugador
+	vidas        int
+	entradas     []Input
+
+	// Resultado al terminar de grabar
+	score      int
+	vida       int
+	huellaMuro uint64
+}
+
+// Fuente de entrada que graba todo lo que lee de otra fuente
+type entradaGrabada struct {
+	fuente    InputSource
+	grabacion *grabacion
+}
+
+func (entrada *entradaGrabada) Leer(velocidad, dt float32) Input {
+	input := Input{}
+	if entrada.fuente != nil {
+		input = entrada.fuente.Leer(velocidad, dt)
+	}
+	entrada.grabacion.entradas = append(entrada.grabacion.entradas, input)
+	return input
+}
+
+// Metodo que anota el resultado de la partida al terminar de grabar
+func (grabacion *grabacion) cerrar(juego *Game) {
+	grabacion.score = juego.jugador.score
+	grabacion.vida = juego.jugador.vida
+	grabacion.huellaMuro = huellaMuro(juego.muro)
+}
+
+// Metodo que indica si la partida termino igual que la grabacion
+func (grabacion *grabacion) coincide(juego *Game) error {
+	if juego.jugador.score != grabacion.score || juego.jugador.vida != grabacion.vida || huellaMuro(juego.muro) != grabacion.huellaMuro {
+		return fmt.Errorf("la repeticion no coincide: score %d (grabado %d), vida %d (grabado %d), muro %x (grabado %x)",
+			juego.jugador.score, grabacion.score, juego.jugador.vida, grabacion.vida, huellaMuro(juego.muro), grabacion.huellaMuro)
+	}
+	return nil
+}
+
+// Huellas de los niveles de la campaña, para grabarlas
+func huellasNiveles(niveles []*nivel) []uint64 {
+	huellas := make([]uint64, len(niveles))
+	for i, nivel := range niveles {
+		huellas[i] = nivel.huella
+	}
+	return huellas
+}
+
+// Huella del estado del muro: posicion y resistencia de cada ladrillo en orden
+func huellaMuro(muro []ladrillo) uint64 {
+	huella := fnv.New64a()
+	var datos [12]byte
+	for _, ladrillo := range muro {
+		binary.LittleEndian.PutUint32(datos[0:], uint32(int32(ladrillo.pos.x)))
+		binary.LittleEndian.PutUint32(datos[4:], uint32(int32(ladrillo.pos.y)))
+		binary.LittleEndian.PutUint32(datos[8:], uint32(int32(ladrillo.resist)))
+		huella.Write(datos[:])
+	}
+	return huella.Sum64()
+}
+
+// Teclas de un tick en un byte, un bit por tecla
+func codificarEntrada(input Input) byte {
+	var codigo byte
+	if input.izquierda {
+		codigo |= 1 << 0
+	}
+	if input.derecha {
+		codigo |= 1 << 1
+	}
+	if input.lanzar {
+		codigo |= 1 << 2
+	}
+	if input.disparar {
+		codigo |= 1 << 3
+	}
+	return codigo
+}
+
+func decodificarEntrada(codigo byte, analogico int64) Input {
+	return Input{
+		izquierda: codigo&(1<<0) != 0,
+		derecha:   codigo&(1<<1) != 0,
+		lanzar:    codigo&(1<<2) != 0,
+		disparar:  codigo&(1<<3) != 0,
+		analogico: float32(analogico) / pasosAnalogico,
+	}
+}
+
+// Guardamos la grabacion en el archivo
+func guardarGrabacion(ruta string, grabacion *grabacion) error {
+	archivo, err := os.Create(ruta)
+	if err != nil {
+		return err
+	}
+
+	escritor := bufio.NewWriter(archivo)
+	var buffer [binary.MaxVarintLen64]byte
+	entero := func(valor int64) {
+		escritor.Write(buffer[:binary.PutVarint(buffer[:], valor)])
+	}
+
+	escritor.WriteString(firmaRepeticion)
+	entero(versionRepeticion)
+	entero(grabacion.semilla)
+	entero(int64(grabacion.frecuencia))
+	entero(int64(len(grabacion.niveles)))
+	for _, huella := range grabacion.niveles {
+		binary.Write(escritor, binary.LittleEndian, huella)
+	}
+	entero(int64(grabacion.nivelInicial))
+	entero(int64(grabacion.velocidad))
+	entero(int64(grabacion.vidas))
+
+	// Tramos de ticks consecutivos con la misma entrada
+	var tramos [][3]int64
+	for _, input := range grabacion.entradas {
+		codigo := int64(codificarEntrada(input))
+		analogico := int64(math.Round(float64(input.analogico) * pasosAnalogico))
+		if ultimo := len(tramos) - 1; ultimo >= 0 && tramos[ultimo][1] == codigo && tramos[ultimo][2] == analogico {
+			tramos[ultimo][0]++
+		} else {
+			tramos = append(tramos, [3]int64{1, codigo, analogico})
+		}
+	}
+	entero(int64(len(tramos)))
+	for _, tramo := range tramos {
+		entero(tramo[0])
+		entero(tramo[1])
+		entero(tramo[2])
+	}
+
+	entero(int64(grabacion.score))
+	entero(int64(grabacion.vida))
+	binary.Write(escritor, binary.LittleEndian, grabacion.huellaMuro)
+
+	if err := escritor.Flush(); err != nil {
+		archivo.Close()
+		return err
+	}
+	return archivo.Close()
+}
+
+// Leemos una grabacion del archivo
+func leerGrabacion(ruta string) (*grabacion, error) {
+	archivo, err := os.Open(ruta)
+	if err != nil {
+		return nil, err
+	}
+	defer archivo.Close()
+
+	lector := bufio.NewReader(archivo)
+	fallo := func(err error) (*grabacion, error) {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("%s: repeticion invalida: %w", ruta, err)
+	}
+
+	firma := make([]byte, len(firmaRepeticion))
+	if _, err := io.ReadFull(lector, firma); err != nil {
+		return fallo(err)
+	}
+	if string(firma) != firmaRepeticion {
+		return fallo(errors.New("no es un archivo de repeticion"))
+	}
+
+	// Lee enteros de a uno y se queda con el primer error
+	var errLectura error
+	entero := func() int64 {
+		if errLectura != nil {
+			return 0
+		}
+		var valor int64
+		valor, errLectura = binary.ReadVarint(lector)
+		return valor
+	}
+
+	if version := entero(); errLectura == nil && version != versionRepeticion {
+		return fallo(fmt.Errorf("version %d no soportada", version))
+	}
+
+	grabacion := &grabacion{}
+	grabacion.semilla = entero()
+	grabacion.frecuencia = int(entero())
+	cantidadNiveles := entero()
+	if errLectura == nil && (cantidadNiveles <= 0 || cantidadNiveles > maxNivelesRepeticion) {
+		errLectura = fmt.Errorf("cantidad de niveles invalida (%d)", cantidadNiveles)
+	}
+	for i := int64(0); i < cantidadNiveles && errLectura == nil; i++ {
+		var huella uint64
+		errLectura = binary.Read(lector, binary.LittleEndian, &huella)
+		grabacion.niveles = append(grabacion.niveles, huella)
+	}
+	grabacion.nivelInicial = int(entero())
+	grabacion.velocidad = int(entero())
+	grabacion.vidas = int(entero())
+
+	tramos := entero()
+	for i := int64(0); i < tramos && errLectura == nil; i++ {
+		repeticiones := entero()
+		codigo := entero()
+		analogico := entero()
+		if repeticiones < 0 || codigo < 0 || codigo > 255 {
+			errLectura = errors.New("tramo de entradas invalido")
+			break
+		}
+		if repeticiones > maxTicksRepeticion-int64(len(grabacion.entradas)) {
+			errLectura = fmt.Errorf("la repeticion tiene mas de %d ticks", maxTicksRepeticion)
+			break
+		}
+		for ; repeticiones > 0; repeticiones-- {
+			grabacion.entradas = append(grabacion.entradas, decodificarEntrada(byte(codigo), analogico))
+		}
+	}
+
+	grabacion.score = int(entero())
+	grabacion.vida = int(entero())
+	if errLectura == nil {
+		errLectura = binary.Read(lector, binary.LittleEndian, &grabacion.huellaMuro)
+	}
+
+	if errLectura != nil {
+		return fallo(errLectura)
+	}
+	if grabacion.frecuencia <= 0 {
+		return fallo(errors.New("frecuencia invalida"))
+	}
+	if grabacion.velocidad <= 0 || grabacion.vidas <= 0 {
+		return fallo(errors.New("configuracion del jugador invalida"))
+	}
+
+	return grabacion, nil
+}
+
+// Armamos la partida tal como empezo la grabacion, con las entradas grabadas como control. Cada nivel grabado se
+// busca por su huella entre los incluidos en el juego y los 'candidatos' (los que se indicaron al reproducir)
+func juegoDesdeGrabacion(grabacion *grabacion, candidatos []*nivel) (*Game, error) {
+	incluidos, err := cargarNivelesIncluidos()
+	if err != nil {
+		return nil, err
+	}
+	porHuella := make(map[uint64]*nivel)
+	for _, candidato := range append(candidatos, incluidos...) {
+		porHuella[candidato.huella] = candidato
+	}
+
+	niveles := make([]*nivel, len(grabacion.niveles))
+	for i, huella := range grabacion.niveles {
+		if niveles[i] = porHuella[huella]; niveles[i] == nil {
+			return nil, fmt.Errorf("la repeticion usa un nivel (huella %016x) que no esta entre los incluidos ni entre los indicados con -nivel o -niveles", huella)
+		}
+	}
+	if grabacion.nivelInicial < 0 || grabacion.nivelInicial >= len(niveles) {
+		return nil, fmt.Errorf("la repeticion empieza en el nivel %d y la campaña tiene %d", grabacion.nivelInicial+1, len(niveles))
+	}
+
+	juego := nuevoJuego(&entradaGuion{pasos: grabacion.entradas}, niveles, grabacion.semilla)
+	juego.fijarFrecuencia(grabacion.frecuencia)
+	juego.configurarJugador(grabacion.velocidad, grabacion.vidas)
+	juego.empezarEn(grabacion.nivelInicial)
+
+	return juego, nil
+}
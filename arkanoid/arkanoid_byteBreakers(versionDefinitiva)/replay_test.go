@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Archivo de repeticion con la cabecera valida y los tramos indicados (repeticiones de la entrada vacia)
+func repeticionConTramos(t *testing.T, tramos ...int64) string {
+	t.Helper()
+	datos := []byte(firmaRepeticion)
+	for _, valor := range []int64{versionRepeticion, 1, ticksPorSegundo, 1} {
+		datos = binary.AppendVarint(datos, valor)
+	}
+	datos = binary.LittleEndian.AppendUint64(datos, 0)
+	for _, valor := range []int64{0, 15, 3, int64(len(tramos))} {
+		datos = binary.AppendVarint(datos, valor)
+	}
+	for _, repeticiones := range tramos {
+		datos = binary.AppendVarint(datos, repeticiones)
+		datos = binary.AppendVarint(datos, 0)
+		datos = binary.AppendVarint(datos, 0)
+	}
+	datos = binary.AppendVarint(datos, 0)
+	datos = binary.AppendVarint(datos, 3)
+	datos = binary.LittleEndian.AppendUint64(datos, 0)
+
+	ruta := filepath.Join(t.TempDir(), "partida.rep")
+	if err := os.WriteFile(ruta, datos, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return ruta
+}
+
+func TestLeerGrabacionTopeTicks(t *testing.T) {
+	casos := []struct {
+		nombre string
+		tramos []int64
+		valida bool
+	}{
+		{"dentro del tope", []int64{100, 200}, true},
+		{"justo en el tope", []int64{maxTicksRepeticion - 1, 1}, true},
+		{"un tramo enorme", []int64{1 << 62}, false},
+		{"tramos que suman mas del tope", []int64{maxTicksRepeticion / 2, maxTicksRepeticion/2 + 1}, false},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nombre, func(t *testing.T) {
+			grabacion, err := leerGrabacion(repeticionConTramos(t, caso.tramos...))
+			if !caso.valida {
+				if err == nil || !strings.Contains(err.Error(), "ticks") {
+					t.Fatalf("se esperaba un error por la cantidad de ticks y se obtuvo %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var total int64
+			for _, repeticiones := range caso.tramos {
+				total += repeticiones
+			}
+			if int64(len(grabacion.entradas)) != total {
+				t.Errorf("se leyeron %d entradas y se esperaban %d", len(grabacion.entradas), total)
+			}
+		})
+	}
+}
+
+// Guion de entradas que mueve la barra de un lado al otro, lanza, dispara y usa el control analogico
+func guionPrueba(ticks int) []Input {
+	pasos := make([]Input, ticks)
+	for i := range pasos {
+		pasos[i] = Input{
+			izquierda: i%240 < 100,
+			derecha:   i%240 >= 140,
+			lanzar:    i%90 == 0,
+			disparar:  i%7 == 0,
+			analogico: float32(i%13-6) / pasosAnalogico,
+		}
+	}
+	return pasos
+}
+
+// Grabamos 'ticks' ticks del guion en la partida, guardamos la grabacion y la volvemos a leer
+func grabarPartida(t *testing.T, juego *Game, ticks int) *grabacion {
+	t.Helper()
+	grabando := &grabacion{
+		semilla:      juego.semilla,
+		frecuencia:   ticksPorSegundo,
+		niveles:      huellasNiveles(juego.niveles),
+		nivelInicial: juego.nivelInicial,
+		velocidad:    int(juego.copiaJugador.vel_x),
+		vidas:        juego.copiaJugador.vida,
+	}
+	juego.jugador.control = &entradaGrabada{&entradaGuion{pasos: guionPrueba(ticks)}, grabando}
+	for juego.tick < ticks {
+		juego.Step()
+	}
+	grabando.cerrar(juego)
+
+	ruta := filepath.Join(t.TempDir(), "partida.rep")
+	if err := guardarGrabacion(ruta, grabando); err != nil {
+		t.Fatal(err)
+	}
+	leida, err := leerGrabacion(ruta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leida
+}
+
+// Reproducimos la grabacion hasta su ultimo tick
+func reproducir(t *testing.T, repeticion *grabacion, candidatos []*nivel) *Game {
+	t.Helper()
+	juego, err := juegoDesdeGrabacion(repeticion, candidatos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for juego.tick < len(repeticion.entradas) {
+		juego.Step()
+	}
+	return juego
+}
+
+// Una partida grabada y reproducida termina igual: mismo tick, score, vidas y muro
+func TestRepeticionIdaYVuelta(t *testing.T) {
+	const ticks = 3000
+	original := juegoPrueba(t, 7)
+	repeticion := grabarPartida(t, original, ticks)
+
+	if len(repeticion.entradas) != ticks {
+		t.Fatalf("la grabacion tiene %d ticks, se esperaban %d", len(repeticion.entradas), ticks)
+	}
+	if original.jugador.score == 0 {
+		t.Fatalf("la partida grabada no rompio ningun ladrillo: la prueba no verifica nada")
+	}
+
+	juego := reproducir(t, repeticion, nil)
+	if juego.tick != original.tick {
+		t.Errorf("la repeticion termino en el tick %d y la partida en el %d", juego.tick, original.tick)
+	}
+	if err := repeticion.coincide(juego); err != nil {
+		t.Error(err)
+	}
+}
+
+// Los niveles se reconocen por su contenido y no por la ruta: una copia del archivo en otra carpeta sirve,
+// y sin el archivo la repeticion no arranca
+func TestRepeticionNivelPropio(t *testing.T) {
+	texto := []byte(encabezadoPrueba + "321\n1.1\n")
+	cargar := func(carpeta string) *nivel {
+		ruta := filepath.Join(carpeta, "propio.txt")
+		if err := os.WriteFile(ruta, texto, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		propio, err := cargarNivel(ruta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return propio
+	}
+
+	original := nuevoJuego(nil, []*nivel{cargar(t.TempDir())}, 3)
+	repeticion := grabarPartida(t, original, 1200)
+
+	if _, err := juegoDesdeGrabacion(repeticion, nil); err == nil || !strings.Contains(err.Error(), "huella") {
+		t.Errorf("sin el nivel se esperaba un error por su huella y se obtuvo %v", err)
+	}
+
+	juego := reproducir(t, repeticion, []*nivel{cargar(t.TempDir())})
+	if err := repeticion.coincide(juego); err != nil {
+		t.Error(err)
+	}
+}